@@ -0,0 +1,254 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/contract"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/pdfcreator"
+)
+
+type StatusRequest struct {
+	Status string `json:"status"`
+	Note   string `json:"note"`
+}
+
+type CancelRequest struct {
+	Reason string `json:"reason"`
+}
+
+type RescheduleRequest struct {
+	EventDate contract.Date `json:"eventDate"`
+	// EventCoverageTime is kept as it is when left out.
+	EventCoverageTime contract.Coverage `json:"eventCoverageTime"`
+	// DeliveryDates move the deliverables, one for each of them in order, and are kept as they are when left out.
+	// They are needed when the new date is not before every delivery date.
+	DeliveryDates []contract.Date `json:"deliveryDates,omitempty"`
+	Note          string          `json:"note"`
+}
+
+func ListContractsHandler(c *fiber.Ctx) error {
+	filter, err := parseContractFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	records, err := contractStore.List(*filter)
+	if err != nil {
+		return contractErrorResponse(c, err, "failed while listing contracts")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"contracts": records,
+	})
+}
+
+func GetContractHandler(c *fiber.Ctx) error {
+	record, err := contractStore.Get(c.Params("id"))
+	if err != nil {
+		return contractErrorResponse(c, err, "failed while reading contract")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(record)
+}
+
+// ContractPdfHandler streams the contract as it stands, or as it was signed once the client signed it, ready to be
+// attached to an email.
+func ContractPdfHandler(c *fiber.Ctx) error {
+	record, err := contractStore.Get(c.Params("id"))
+	if err != nil {
+		return contractErrorResponse(c, err, "failed while reading contract")
+	}
+
+	if record.Signature != nil {
+		return sendSignedContract(c, record)
+	}
+
+	return sendContractPdf(c, record, "attachment", "contract")
+}
+
+func UpdateContractStatusHandler(c *fiber.Ctx) error {
+	var request StatusRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to parse JSON",
+		})
+	}
+
+	status, err := contract.ParseStatus(request.Status)
+	if err != nil {
+		return contractErrorResponse(c, err, "failed while parsing status")
+	}
+
+	if status == contract.StatusCancelled {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "use the cancel endpoint to cancel a contract",
+		})
+	}
+
+	return updateContract(c, func(record *contract.Record) error {
+		return record.Transition(status, request.Note)
+	})
+}
+
+func CancelContractHandler(c *fiber.Ctx) error {
+	var request CancelRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to parse JSON",
+		})
+	}
+
+	if request.Reason == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "cancellation reason is required",
+		})
+	}
+
+	return updateContract(c, func(record *contract.Record) error {
+		return record.Transition(contract.StatusCancelled, request.Reason)
+	})
+}
+
+func RescheduleContractHandler(c *fiber.Ctx) error {
+	var request RescheduleRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to parse JSON",
+		})
+	}
+
+	if err := validateEventDate(request.EventDate); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// The rescheduled contract is checked like a new one, so the event stays ahead of its deliveries
+	return updateContract(c, func(record *contract.Record) error {
+		err := record.Reschedule(request.EventDate, request.EventCoverageTime, request.DeliveryDates, request.Note)
+		if err != nil {
+			return err
+		}
+		if err := validateSchedule(&record.Contract); err != nil {
+			return fmt.Errorf("%w : %v", contract.ErrInvalidReschedule, err)
+		}
+		return nil
+	})
+}
+
+// updateContract loads the contract named in the route, applies change to it and saves it back.
+func updateContract(c *fiber.Ctx, change func(record *contract.Record) error) error {
+	record, err := contractStore.Get(c.Params("id"))
+	if err != nil {
+		return contractErrorResponse(c, err, "failed while reading contract")
+	}
+
+	if err := change(record); err != nil {
+		return contractErrorResponse(c, err, "failed while updating contract")
+	}
+
+	if err := contractStore.Update(record); err != nil {
+		return contractErrorResponse(c, err, "failed while saving contract")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(record)
+}
+
+// sendContractPdf renders the contract in memory and sends it with the given content disposition.
+func sendContractPdf(c *fiber.Ctx, record *contract.Record, disposition string, prefix string) error {
+	document, err := pdfcreator.RenderContract(record)
+	if err != nil {
+		return contractErrorResponse(c, err, "failed while rendering contract pdf")
+	}
+
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("%s; filename=%q", disposition, pdfFileName(record, prefix)))
+	c.Type("pdf")
+	return c.Send(document)
+}
+
+func pdfFileName(record *contract.Record, prefix string) string {
+	return fmt.Sprintf("%s-%s-%s.pdf", prefix, strings.ReplaceAll(record.Contract.EventDetails.EventName, " ", "_"), strings.ReplaceAll(record.Contract.ClientDetails.ClientName, " ", "_"))
+}
+
+// contractErrorResponse maps store and lifecycle errors to the matching HTTP status.
+func contractErrorResponse(c *fiber.Ctx, err error, message string) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, contract.ErrNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, contract.ErrInvalidStatus), errors.Is(err, contract.ErrInvalidPayment),
+		errors.Is(err, contract.ErrInvalidOvertime), errors.Is(err, contract.ErrInvalidReschedule):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, contract.ErrInvalidTransition), errors.Is(err, contract.ErrRescheduleNotAllowed),
+		errors.Is(err, contract.ErrConflict):
+		status = fiber.StatusConflict
+	}
+
+	if status == fiber.StatusInternalServerError {
+		logrus.New().WithError(err).WithField("id", c.Params("id")).Errorf(message)
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"error": fmt.Errorf("%s with err : %w", message, err).Error(),
+	})
+}
+
+func parseContractFilter(c *fiber.Ctx) (*contract.Filter, error) {
+	filter := &contract.Filter{
+		ClientEmail: c.Query("clientEmail"),
+		EventName:   c.Query("eventName"),
+	}
+
+	var err error
+	if status := c.Query("status"); status != "" {
+		filter.Status, err = contract.ParseStatus(status)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if from := c.Query("eventDateFrom"); from != "" {
+		filter.EventDateFrom, err = time.Parse(contract.EventDateLayout, from)
+		if err != nil {
+			return nil, fmt.Errorf("eventDateFrom should be formatted as %s", contract.EventDateLayout)
+		}
+	}
+
+	if to := c.Query("eventDateTo"); to != "" {
+		filter.EventDateTo, err = time.Parse(contract.EventDateLayout, to)
+		if err != nil {
+			return nil, fmt.Errorf("eventDateTo should be formatted as %s", contract.EventDateLayout)
+		}
+	}
+
+	if needsReview := c.Query("needsReview"); needsReview != "" {
+		filter.NeedsReview, err = strconv.ParseBool(needsReview)
+		if err != nil {
+			return nil, errors.New("needsReview should be true or false")
+		}
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit < 0 {
+			return nil, errors.New("limit should be a positive number")
+		}
+	}
+
+	if offset := c.Query("offset"); offset != "" {
+		filter.Offset, err = strconv.Atoi(offset)
+		if err != nil || filter.Offset < 0 {
+			return nil, errors.New("offset should be a positive number")
+		}
+	}
+
+	return filter, nil
+}
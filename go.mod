@@ -4,10 +4,14 @@ go 1.19
 
 require (
 	github.com/gofiber/fiber/v2 v2.44.0
+	github.com/google/uuid v1.3.0
 	github.com/johnfercher/maroto v0.41.0
+	github.com/jung-kurt/gofpdf v1.16.2
 	github.com/karmdip-mi/go-fitz v0.0.0-20210702102225-a530a79566e9
+	github.com/mattn/go-sqlite3 v1.14.16
 	github.com/sirupsen/logrus v1.9.0
 	google.golang.org/api v0.120.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -18,10 +22,8 @@ require (
 	github.com/golang/groupcache v0.0.0-20200121045136-8c9f03a8e57e // indirect
 	github.com/golang/protobuf v1.5.3 // indirect
 	github.com/google/s2a-go v0.1.2 // indirect
-	github.com/google/uuid v1.3.0 // indirect
 	github.com/googleapis/enterprise-certificate-proxy v0.2.3 // indirect
 	github.com/googleapis/gax-go/v2 v2.8.0 // indirect
-	github.com/jung-kurt/gofpdf v1.16.2 // indirect
 	github.com/klauspost/compress v1.16.3 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.18 // indirect
@@ -0,0 +1,278 @@
+package branding
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/johnfercher/maroto/pkg/color"
+	"github.com/johnfercher/maroto/pkg/consts"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// FontFamily is the family the custom fonts of a brand are registered under.
+const FontFamily = "brand"
+
+// FontLocation is where documents look for font files. The fonts of a brand are absolute paths, which gofpdf would
+// otherwise look for under the working directory.
+const FontLocation = "/"
+
+// defaultServices is what the studio offers, when the brand does not say.
+const defaultServices = "photography and videography"
+
+var ErrInvalidBranding = errors.New("invalid branding")
+
+// Branding is who the contracts, invoices and receipts are issued by and how they look.
+type Branding struct {
+	Studio
+	// Logo is a PNG or JPEG file printed at the top of every page, relative to the branding file.
+	Logo string `json:"logo,omitempty"`
+	// AccentColor is the hex color of titles and rules, e.g. #c8102e. Black when empty.
+	AccentColor string `json:"accentColor,omitempty"`
+	Fonts       Fonts  `json:"fonts,omitempty"`
+
+	accent     color.Color
+	logoImage  string
+	logoFormat consts.Extension
+}
+
+// Studio is who the documents are issued by. Contracts keep the studio they were issued by, so a later change of
+// name does not alter the agreement.
+type Studio struct {
+	// Name is what the studio goes by in the documents, e.g. Red Dot Studios.
+	Name string `json:"name"`
+	// LegalName is who the agreement is made with, the name when empty.
+	LegalName string `json:"legalName,omitempty"`
+	// Services is what the studio offers, as in "a Massachusetts based photography and videography Service".
+	Services string `json:"services,omitempty"`
+	Location string `json:"location"`
+	Address  string `json:"address,omitempty"`
+	Phone    string `json:"phone,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Website  string `json:"website,omitempty"`
+	// Zelle is where the booking fee and invoices are paid to.
+	Zelle string `json:"zelle"`
+}
+
+// Fonts are TrueType files the documents are printed in instead of Arial, relative to the branding file. Styles
+// left out are printed in the regular font.
+type Fonts struct {
+	Regular    string `json:"regular,omitempty"`
+	Bold       string `json:"bold,omitempty"`
+	Italic     string `json:"italic,omitempty"`
+	BoldItalic string `json:"boldItalic,omitempty"`
+}
+
+var (
+	brandLock sync.RWMutex
+	// brand is the branding documents are rendered with.
+	brand = Branding{
+		Studio: Studio{
+			Name:      "Red Dot Studios",
+			LegalName: "Red Dot Studios",
+			Services:  defaultServices,
+			Location:  "Massachusetts",
+			Zelle:     "774-448-8352",
+		},
+		AccentColor: "#c8102e",
+		accent:      color.Color{Red: 200, Green: 16, Blue: 46},
+	}
+)
+
+// Load replaces the built in branding with the one in a JSON file. The logo and fonts it names are read up front,
+// so a broken file fails here rather than when a document is rendered.
+func Load(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed while reading branding file with err : %w", err)
+	}
+
+	var loaded Branding
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return fmt.Errorf("failed while parsing branding file with err : %w", err)
+	}
+
+	if err := loaded.prepare(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	brandLock.Lock()
+	defer brandLock.Unlock()
+	brand = loaded
+
+	return nil
+}
+
+// Current is the branding documents are rendered with.
+func Current() Branding {
+	brandLock.RLock()
+	defer brandLock.RUnlock()
+
+	return brand
+}
+
+// IssuedBy is the branding documents of a contract are rendered with, the current one printed with the details of
+// the studio the contract was issued by. Contracts issued before the studio was kept have none and are printed with
+// the current details.
+func IssuedBy(studio *Studio) Branding {
+	brand := Current()
+	if studio != nil {
+		brand.Studio = *studio
+	}
+	return brand
+}
+
+// Accent is the color of titles and rules.
+func (b Branding) Accent() color.Color {
+	return b.accent
+}
+
+// LogoImage is the logo encoded in base64 and its format, empty when the brand has no logo.
+func (b Branding) LogoImage() (string, consts.Extension) {
+	return b.logoImage, b.logoFormat
+}
+
+// Contact is how clients reach the studio, its phone, email and website on one line.
+func (studio Studio) Contact() string {
+	var contact []string
+	for _, value := range []string{studio.Phone, studio.Email, studio.Website} {
+		if value != "" {
+			contact = append(contact, value)
+		}
+	}
+	return strings.Join(contact, " | ")
+}
+
+// Files are the font files of each style, the regular one standing in for the styles left out. It is empty when
+// the brand prints in Arial.
+func (fonts Fonts) Files() map[consts.Style]string {
+	if fonts.Regular == "" {
+		return nil
+	}
+
+	files := map[consts.Style]string{
+		consts.Normal:     fonts.Regular,
+		consts.Bold:       fonts.Bold,
+		consts.Italic:     fonts.Italic,
+		consts.BoldItalic: fonts.BoldItalic,
+	}
+	for style, file := range files {
+		if file == "" {
+			files[style] = fonts.Regular
+		}
+	}
+	return files
+}
+
+// prepare checks the branding, fills in what it leaves out and reads its logo and fonts from dir.
+func (b *Branding) prepare(dir string) error {
+	if b.Name == "" {
+		return fmt.Errorf("%w : name is required", ErrInvalidBranding)
+	}
+	if b.Location == "" {
+		return fmt.Errorf("%w : location is required", ErrInvalidBranding)
+	}
+	if b.Zelle == "" {
+		return fmt.Errorf("%w : zelle is required", ErrInvalidBranding)
+	}
+	if b.LegalName == "" {
+		b.LegalName = b.Name
+	}
+	if b.Services == "" {
+		b.Services = defaultServices
+	}
+
+	if b.AccentColor != "" {
+		accent, err := parseColor(b.AccentColor)
+		if err != nil {
+			return err
+		}
+		b.accent = accent
+	}
+
+	if b.Logo != "" {
+		if err := b.readLogo(resolve(dir, b.Logo)); err != nil {
+			return err
+		}
+	}
+
+	fonts := []*string{&b.Fonts.Regular, &b.Fonts.Bold, &b.Fonts.Italic, &b.Fonts.BoldItalic}
+	for _, file := range fonts {
+		if *file != "" {
+			*file = resolve(dir, *file)
+		}
+	}
+	if b.Fonts.Regular == "" && b.Fonts != (Fonts{}) {
+		return fmt.Errorf("%w : fonts need a regular font", ErrInvalidBranding)
+	}
+
+	// Fonts are only read by gofpdf as documents are rendered, so they are tried out on a blank one first
+	for style, file := range b.Fonts.Files() {
+		document := gofpdf.New("P", "mm", "Letter", FontLocation)
+		document.AddUTF8Font(FontFamily, string(style), file)
+		if err := document.Error(); err != nil {
+			return fmt.Errorf("%w : font %s : %v", ErrInvalidBranding, file, err)
+		}
+	}
+
+	return nil
+}
+
+// readLogo reads the logo into memory, so it is not read from disk for every page.
+func (b *Branding) readLogo(path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		b.logoFormat = consts.Png
+	case ".jpg", ".jpeg":
+		b.logoFormat = consts.Jpg
+	default:
+		return fmt.Errorf("%w : logo %s is not a PNG or JPEG image", ErrInvalidBranding, path)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%w : logo : %v", ErrInvalidBranding, err)
+	}
+
+	document := gofpdf.New("P", "mm", "Letter", "")
+	document.RegisterImageOptionsReader(path, gofpdf.ImageOptions{ImageType: string(b.logoFormat)}, bytes.NewReader(data))
+	if err := document.Error(); err != nil {
+		return fmt.Errorf("%w : logo %s : %v", ErrInvalidBranding, path, err)
+	}
+	b.logoImage = base64.StdEncoding.EncodeToString(data)
+
+	return nil
+}
+
+// parseColor reads a hex color such as #c8102e.
+func parseColor(value string) (color.Color, error) {
+	hex := strings.TrimPrefix(value, "#")
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 6 || err != nil {
+		return color.Color{}, fmt.Errorf("%w : accent color %s is not a hex color such as #c8102e", ErrInvalidBranding, value)
+	}
+
+	return color.Color{
+		Red:   int(rgb >> 16 & 0xff),
+		Green: int(rgb >> 8 & 0xff),
+		Blue:  int(rgb & 0xff),
+	}, nil
+}
+
+// resolve makes paths in the branding file relative to the directory it is in, and absolute.
+func resolve(dir, path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
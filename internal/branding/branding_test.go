@@ -0,0 +1,136 @@
+package branding
+
+import (
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/johnfercher/maroto/pkg/color"
+	"github.com/johnfercher/maroto/pkg/consts"
+)
+
+// restoreBranding puts the built in branding back once the test is done.
+func restoreBranding(t *testing.T) {
+	builtIn := Current()
+	t.Cleanup(func() {
+		brandLock.Lock()
+		brand = builtIn
+		brandLock.Unlock()
+	})
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func writeLogo(t *testing.T, dir string) {
+	t.Helper()
+
+	file, err := os.Create(filepath.Join(dir, "logo.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := png.Encode(file, image.NewRGBA(image.Rect(0, 0, 40, 20))); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	restoreBranding(t)
+	dir := t.TempDir()
+	writeLogo(t, dir)
+
+	err := Load(writeFile(t, dir, "brand.json", `{"name": "Blue Lens", "location": "Rhode Island", "zelle": "401-555-0100",
+		"phone": "401-555-0100", "website": "bluelens.example", "logo": "logo.png", "accentColor": "#0A64c8"}`))
+	if err != nil {
+		t.Fatalf("Load : %v", err)
+	}
+
+	brand := Current()
+	if brand.Name != "Blue Lens" || brand.LegalName != "Blue Lens" || brand.Services != defaultServices {
+		t.Errorf("branding = %+v", brand)
+	}
+	if brand.Accent() != (color.Color{Red: 10, Green: 100, Blue: 200}) {
+		t.Errorf("accent = %+v", brand.Accent())
+	}
+	if logo, format := brand.LogoImage(); logo == "" || format != consts.Png {
+		t.Errorf("logo is %d bytes of %s", len(logo), format)
+	}
+	if contact := brand.Contact(); contact != "401-555-0100 | bluelens.example" {
+		t.Errorf("contact = %q", contact)
+	}
+}
+
+func TestIssuedBy(t *testing.T) {
+	restoreBranding(t)
+	issuedBy := Current().Studio
+	dir := t.TempDir()
+	writeLogo(t, dir)
+	if err := Load(writeFile(t, dir, "brand.json", `{"name": "Blue Lens", "location": "Rhode Island", "zelle": "401-555-0100", "logo": "logo.png"}`)); err != nil {
+		t.Fatalf("Load : %v", err)
+	}
+
+	// Contracts keep the studio they were issued by and are printed in the current look
+	brand := IssuedBy(&issuedBy)
+	if brand.Name != "Red Dot Studios" || brand.Location != "Massachusetts" {
+		t.Errorf("branding of an issued contract = %+v", brand.Studio)
+	}
+	if logo, _ := brand.LogoImage(); logo == "" {
+		t.Error("branding of an issued contract has no logo")
+	}
+	if brand := IssuedBy(nil); brand.Name != "Blue Lens" {
+		t.Errorf("branding of a contract without a studio is %q", brand.Name)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	restoreBranding(t)
+	dir := t.TempDir()
+	writeLogo(t, dir)
+	writeFile(t, dir, "logo.gif", "GIF89a")
+	writeFile(t, dir, "broken.png", "not a png")
+	writeFile(t, dir, "font.ttf", "not a font")
+
+	tests := map[string]string{
+		"no name":             `{"location": "Rhode Island", "zelle": "401-555-0100"}`,
+		"no location":         `{"name": "Blue Lens", "zelle": "401-555-0100"}`,
+		"no zelle":            `{"name": "Blue Lens", "location": "Rhode Island"}`,
+		"short color":         `{"name": "Blue Lens", "location": "Rhode Island", "zelle": "401-555-0100", "accentColor": "#fff"}`,
+		"named color":         `{"name": "Blue Lens", "location": "Rhode Island", "zelle": "401-555-0100", "accentColor": "blue"}`,
+		"gif logo":            `{"name": "Blue Lens", "location": "Rhode Island", "zelle": "401-555-0100", "logo": "logo.gif"}`,
+		"missing logo":        `{"name": "Blue Lens", "location": "Rhode Island", "zelle": "401-555-0100", "logo": "missing.png"}`,
+		"broken logo":         `{"name": "Blue Lens", "location": "Rhode Island", "zelle": "401-555-0100", "logo": "broken.png"}`,
+		"bold without normal": `{"name": "Blue Lens", "location": "Rhode Island", "zelle": "401-555-0100", "fonts": {"bold": "font.ttf"}}`,
+		"broken font":         `{"name": "Blue Lens", "location": "Rhode Island", "zelle": "401-555-0100", "fonts": {"regular": "font.ttf"}}`,
+	}
+	for name, content := range tests {
+		if err := Load(writeFile(t, dir, "brand.json", content)); !errors.Is(err, ErrInvalidBranding) {
+			t.Errorf("%s : Load returned %v, want ErrInvalidBranding", name, err)
+		}
+	}
+
+	if Current().Name != "Red Dot Studios" {
+		t.Errorf("branding that failed to load replaced the built in one with %q", Current().Name)
+	}
+}
+
+func TestFontsFiles(t *testing.T) {
+	if files := (Fonts{}).Files(); files != nil {
+		t.Errorf("files of no fonts = %v", files)
+	}
+
+	files := Fonts{Regular: "/fonts/regular.ttf", Bold: "/fonts/bold.ttf"}.Files()
+	if files[consts.Normal] != "/fonts/regular.ttf" || files[consts.Bold] != "/fonts/bold.ttf" || files[consts.Italic] != "/fonts/regular.ttf" {
+		t.Errorf("files = %v", files)
+	}
+}
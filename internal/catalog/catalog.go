@@ -0,0 +1,241 @@
+package catalog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/contract"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/money"
+)
+
+var (
+	ErrUnknownPackage = errors.New("unknown package")
+	ErrInvalidPackage = errors.New("invalid package")
+)
+
+// Package is a bundle of coverage and deliverables contracts can be created from instead of typing them out.
+type Package struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// EventType is the type of the events the package is for, which decides the terms of its contracts.
+	EventType contract.EventType `json:"eventType,omitempty"`
+	// Currency is what the package is priced in, US dollars when empty.
+	Currency      string      `json:"currency,omitempty"`
+	Price         money.Money `json:"price"`
+	PerHourExtra  money.Money `json:"perHourExtra"`
+	CoverageHours float64     `json:"coverageHours"`
+	// PaymentPolicy names the pricing policy of contracts for the package, the standard one when empty.
+	PaymentPolicy string        `json:"paymentPolicy,omitempty"`
+	Deliverables  []Deliverable `json:"deliverables"`
+}
+
+// Deliverable is one of the deliverables of a package, due a number of days after the event.
+type Deliverable struct {
+	Description  string `json:"description"`
+	Quantity     string `json:"quantity"`
+	Mode         string `json:"mode"`
+	DeliveryDays int    `json:"deliveryDays"`
+}
+
+var (
+	packagesLock sync.RWMutex
+	// packages are the packages contracts can be created from, keyed by ID.
+	packages = map[string]Package{
+		"wedding-gold": {
+			Name:          "Wedding Gold",
+			EventType:     contract.EventTypeWedding,
+			Currency:      money.DefaultCurrency,
+			Description:   "Full day photography and videography with a printed album",
+			Price:         money.FromMajor(4500, money.DefaultCurrency),
+			PerHourExtra:  money.FromMajor(300, money.DefaultCurrency),
+			CoverageHours: 8,
+			Deliverables: []Deliverable{
+				{Description: "Edited photos", Quantity: "600", Mode: "Online gallery", DeliveryDays: 60},
+				{Description: "Highlight video", Quantity: "1 (5-7 minutes)", Mode: "Online gallery", DeliveryDays: 90},
+				{Description: "Full length video", Quantity: "1", Mode: "Online gallery", DeliveryDays: 120},
+				{Description: "Printed album", Quantity: "1 (40 pages)", Mode: "Hard copy", DeliveryDays: 120},
+			},
+		},
+		"event-silver": {
+			Name:          "Event Silver",
+			EventType:     contract.EventTypeEvent,
+			Currency:      money.DefaultCurrency,
+			Description:   "Photography and a highlight video for parties and corporate events",
+			Price:         money.FromMajor(1200, money.DefaultCurrency),
+			PerHourExtra:  money.FromMajor(200, money.DefaultCurrency),
+			CoverageHours: 4,
+			Deliverables: []Deliverable{
+				{Description: "Edited photos", Quantity: "250", Mode: "Online gallery", DeliveryDays: 30},
+				{Description: "Highlight video", Quantity: "1 (3-4 minutes)", Mode: "Online gallery", DeliveryDays: 45},
+			},
+		},
+		"birthday-basic": {
+			Name:          "Birthday Basic",
+			EventType:     contract.EventTypeSmall,
+			Currency:      money.DefaultCurrency,
+			Description:   "Photography for birthdays and small gatherings",
+			Price:         money.FromMajor(600, money.DefaultCurrency),
+			PerHourExtra:  money.FromMajor(150, money.DefaultCurrency),
+			CoverageHours: 3,
+			Deliverables: []Deliverable{
+				{Description: "Edited photos", Quantity: "150", Mode: "Online gallery", DeliveryDays: 21},
+			},
+		},
+	}
+)
+
+// LoadPackages adds the packages in a JSON file keyed by ID, replacing built in packages of the same ID.
+func LoadPackages(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed while reading packages file with err : %w", err)
+	}
+
+	var loaded map[string]Package
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return fmt.Errorf("failed while parsing packages file with err : %w", err)
+	}
+
+	for id, pkg := range loaded {
+		if pkg.Currency == "" {
+			pkg.Currency = pkg.Price.Or(money.DefaultCurrency).Currency
+		}
+		pkg.Price = pkg.Price.Or(pkg.Currency)
+		pkg.PerHourExtra = pkg.PerHourExtra.Or(pkg.Currency)
+		if err := pkg.validate(); err != nil {
+			return fmt.Errorf("package %s : %w", id, err)
+		}
+		loaded[id] = pkg
+	}
+
+	packagesLock.Lock()
+	defer packagesLock.Unlock()
+	for id, pkg := range loaded {
+		packages[id] = pkg
+	}
+
+	return nil
+}
+
+// LookupPackage returns the package with the given ID.
+func LookupPackage(id string) (Package, error) {
+	packagesLock.RLock()
+	defer packagesLock.RUnlock()
+
+	pkg, ok := packages[id]
+	if !ok {
+		return Package{}, fmt.Errorf("%w : %s", ErrUnknownPackage, id)
+	}
+	pkg.ID = id
+	return pkg, nil
+}
+
+// Packages lists every package ordered by name, for the UI to offer.
+func Packages() []Package {
+	packagesLock.RLock()
+	list := make([]Package, 0, len(packages))
+	for id, pkg := range packages {
+		pkg.ID = id
+		list = append(list, pkg)
+	}
+	packagesLock.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// Apply fills in what the contract leaves out from the package it names. Anything the contract sets itself is
+// kept, so packages can be tweaked per contract. Coverage of the event and of each of its sessions ends the
+// package's coverage hours after it starts, and deliverable dates are counted from the last day of the event. Split
+// events should have taken their date and coverage from their first session already.
+func Apply(details *contract.Contract) error {
+	if details.PackageID == "" {
+		return nil
+	}
+
+	pkg, err := LookupPackage(details.PackageID)
+	if err != nil {
+		return err
+	}
+
+	if details.EventDetails.EventType == "" {
+		details.EventDetails.EventType = pkg.EventType
+	}
+
+	coverages := []*contract.Coverage{&details.EventDetails.EventCoverageTime}
+	for i := range details.EventDetails.Sessions {
+		coverages = append(coverages, &details.EventDetails.Sessions[i].CoverageTime)
+	}
+	for _, coverage := range coverages {
+		if coverage.Start.Valid() && !coverage.End.Valid() {
+			coverage.SetDuration(time.Duration(pkg.CoverageHours * float64(time.Hour)))
+		}
+	}
+
+	payment := &details.PaymentDetails
+	if payment.TotalAmount.IsZero() {
+		payment.TotalAmount = pkg.Price
+	}
+	if payment.PerHourExtra.IsZero() {
+		payment.PerHourExtra = pkg.PerHourExtra
+	}
+	if payment.PaymentPolicy == "" && len(payment.Installments) == 0 {
+		payment.PaymentPolicy = pkg.PaymentPolicy
+	}
+
+	if len(details.DeliverableDetails) == 0 {
+		for _, deliverable := range pkg.Deliverables {
+			details.DeliverableDetails = append(details.DeliverableDetails, contract.Deliverable{
+				Description:  deliverable.Description,
+				Quantity:     deliverable.Quantity,
+				Mode:         deliverable.Mode,
+				DeliveryDate: details.EventDetails.LastDate().AddDays(deliverable.DeliveryDays),
+			})
+		}
+	}
+
+	return nil
+}
+
+func (pkg Package) validate() error {
+	switch {
+	case pkg.Name == "":
+		return fmt.Errorf("%w : a name is required", ErrInvalidPackage)
+	case !money.Supported(pkg.Currency):
+		return fmt.Errorf("%w : currency %s is not supported", ErrInvalidPackage, pkg.Currency)
+	case pkg.Price.Currency != pkg.Currency || pkg.PerHourExtra.Currency != pkg.Currency:
+		return fmt.Errorf("%w : every amount should be in %s", ErrInvalidPackage, pkg.Currency)
+	case pkg.Price.Amount <= 0:
+		return fmt.Errorf("%w : price should be greater than zero", ErrInvalidPackage)
+	case pkg.PerHourExtra.IsNegative():
+		return fmt.Errorf("%w : per hour extra cannot be negative", ErrInvalidPackage)
+	case pkg.CoverageHours <= 0:
+		return fmt.Errorf("%w : coverage hours should be greater than zero", ErrInvalidPackage)
+	case pkg.CoverageHours >= 24:
+		return fmt.Errorf("%w : coverage hours should be less than a day", ErrInvalidPackage)
+	case len(pkg.Deliverables) == 0:
+		return fmt.Errorf("%w : at least one deliverable is required", ErrInvalidPackage)
+	}
+
+	if _, err := contract.ParseEventType(string(pkg.EventType)); err != nil {
+		return fmt.Errorf("%w : %s", ErrInvalidPackage, err)
+	}
+
+	for i, deliverable := range pkg.Deliverables {
+		if deliverable.Description == "" || deliverable.Quantity == "" || deliverable.Mode == "" {
+			return fmt.Errorf("%w : deliverable %d needs a description, quantity and mode", ErrInvalidPackage, i+1)
+		}
+		if deliverable.DeliveryDays < 0 {
+			return fmt.Errorf("%w : deliverable %d delivery days cannot be negative", ErrInvalidPackage, i+1)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package catalog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/contract"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/money"
+)
+
+func dollars(amount int64) money.Money {
+	return money.FromMajor(amount, money.DefaultCurrency)
+}
+
+func startingAt(t *testing.T, start string) contract.Coverage {
+	t.Helper()
+
+	clock, err := contract.ParseClock(start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return contract.Coverage{Start: clock}
+}
+
+func TestApply(t *testing.T) {
+	eventDate := contract.NewDate(time.Date(2030, 5, 20, 0, 0, 0, 0, time.UTC))
+	details := &contract.Contract{
+		PackageID:    "event-silver",
+		EventDetails: contract.EventDetails{EventName: "Launch Party", EventDate: eventDate, EventCoverageTime: startingAt(t, "10pm")},
+	}
+	if err := Apply(details); err != nil {
+		t.Fatalf("Apply : %v", err)
+	}
+
+	if details.EventDetails.EventType != contract.EventTypeEvent {
+		t.Errorf("event type = %q", details.EventDetails.EventType)
+	}
+	// The package's four hours run past midnight
+	if coverage := details.EventDetails.EventCoverageTime; coverage.End.String() != "2:00 AM" || !coverage.NextDay || coverage.Validate() != nil {
+		t.Errorf("coverage = %s", coverage.Format(contract.Date{}))
+	}
+	if details.PaymentDetails.TotalAmount != dollars(1200) || details.PaymentDetails.PerHourExtra != dollars(200) {
+		t.Errorf("total %s at %s per extra hour", details.PaymentDetails.TotalAmount, details.PaymentDetails.PerHourExtra)
+	}
+
+	deliverables := details.DeliverableDetails
+	if len(deliverables) != 2 || deliverables[0].DeliveryDate != eventDate.AddDays(30) || deliverables[1].DeliveryDate != eventDate.AddDays(45) {
+		t.Errorf("deliverables = %+v", deliverables)
+	}
+}
+
+func TestApplyKeepsContractDetails(t *testing.T) {
+	coverage, _ := contract.ParseCoverage("2pm - 4pm")
+	details := &contract.Contract{
+		PackageID: "wedding-gold",
+		EventDetails: contract.EventDetails{
+			EventDate:         contract.NewDate(time.Date(2030, 5, 20, 0, 0, 0, 0, time.UTC)),
+			EventCoverageTime: coverage,
+			EventType:         contract.EventTypeEvent,
+		},
+		PaymentDetails:     contract.PaymentDetails{TotalAmount: dollars(4000), PaymentPolicy: "no-deposit"},
+		DeliverableDetails: []contract.Deliverable{{Description: "Edited photos", Quantity: "100", Mode: "Online gallery"}},
+	}
+	if err := Apply(details); err != nil {
+		t.Fatalf("Apply : %v", err)
+	}
+
+	if details.EventDetails.EventType != contract.EventTypeEvent || details.EventDetails.EventCoverageTime.End.String() != "4:00 PM" {
+		t.Errorf("event details = %+v", details.EventDetails)
+	}
+	payment := details.PaymentDetails
+	if payment.TotalAmount != dollars(4000) || payment.PerHourExtra != dollars(300) || payment.PaymentPolicy != "no-deposit" {
+		t.Errorf("payment details = %+v", payment)
+	}
+	if len(details.DeliverableDetails) != 1 || details.DeliverableDetails[0].Quantity != "100" {
+		t.Errorf("deliverables = %+v", details.DeliverableDetails)
+	}
+
+	// Contracts that do not name a package are left as they are
+	plain := &contract.Contract{}
+	if err := Apply(plain); err != nil || !plain.PaymentDetails.TotalAmount.IsZero() {
+		t.Errorf("Apply without a package = %v, total %s", err, plain.PaymentDetails.TotalAmount)
+	}
+	if err := Apply(&contract.Contract{PackageID: "platinum"}); !errors.Is(err, ErrUnknownPackage) {
+		t.Errorf("Apply of an unknown package returned %v, want ErrUnknownPackage", err)
+	}
+}
+
+func TestLoadPackages(t *testing.T) {
+	// The loaded packages are put back as they were for the other tests
+	packagesLock.RLock()
+	builtIn := make(map[string]Package, len(packages))
+	for id, pkg := range packages {
+		builtIn[id] = pkg
+	}
+	packagesLock.RUnlock()
+	t.Cleanup(func() {
+		packagesLock.Lock()
+		packages = builtIn
+		packagesLock.Unlock()
+	})
+
+	write := func(content string) string {
+		path := filepath.Join(t.TempDir(), "packages.json")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	err := LoadPackages(write(`{"portrait": {"name": "Portrait", "eventType": "small", "price": 300, "perHourExtra": 100,
+		"coverageHours": 1, "deliverables": [{"description": "Edited photos", "quantity": "30", "mode": "Online gallery"}]}}`))
+	if err != nil {
+		t.Fatalf("LoadPackages : %v", err)
+	}
+	portrait, err := LookupPackage("portrait")
+	if err != nil || portrait.ID != "portrait" || portrait.Currency != money.DefaultCurrency || portrait.Price != dollars(300) {
+		t.Errorf("LookupPackage = %+v, %v", portrait, err)
+	}
+	if list := Packages(); len(list) != 4 || list[0].Name != "Birthday Basic" || list[3].Name != "Wedding Gold" {
+		t.Errorf("packages are listed as %+v", list)
+	}
+
+	tests := map[string]string{
+		"no name":             `{"bad": {"price": 300, "coverageHours": 1, "deliverables": [{"description": "A", "quantity": "1", "mode": "B"}]}}`,
+		"no price":            `{"bad": {"name": "Bad", "coverageHours": 1, "deliverables": [{"description": "A", "quantity": "1", "mode": "B"}]}}`,
+		"no coverage":         `{"bad": {"name": "Bad", "price": 300, "deliverables": [{"description": "A", "quantity": "1", "mode": "B"}]}}`,
+		"no deliverables":     `{"bad": {"name": "Bad", "price": 300, "coverageHours": 1}}`,
+		"coverage of a day":   `{"bad": {"name": "Bad", "price": 300, "coverageHours": 24, "deliverables": [{"description": "A", "quantity": "1", "mode": "B"}]}}`,
+		"unknown event type":  `{"bad": {"name": "Bad", "eventType": "party", "price": 300, "coverageHours": 1, "deliverables": [{"description": "A", "quantity": "1", "mode": "B"}]}}`,
+		"negative delivery":   `{"bad": {"name": "Bad", "price": 300, "coverageHours": 1, "deliverables": [{"description": "A", "quantity": "1", "mode": "B", "deliveryDays": -1}]}}`,
+		"different currency":  `{"bad": {"name": "Bad", "currency": "EUR", "price": {"amount": 30000, "currency": "USD"}, "coverageHours": 1, "deliverables": [{"description": "A", "quantity": "1", "mode": "B"}]}}`,
+		"deliverable no mode": `{"bad": {"name": "Bad", "price": 300, "coverageHours": 1, "deliverables": [{"description": "A", "quantity": "1"}]}}`,
+	}
+	for name, content := range tests {
+		if err := LoadPackages(write(content)); !errors.Is(err, ErrInvalidPackage) {
+			t.Errorf("%s : LoadPackages returned %v, want ErrInvalidPackage", name, err)
+		}
+	}
+	if _, err := LookupPackage("bad"); !errors.Is(err, ErrUnknownPackage) {
+		t.Errorf("an invalid package was loaded, lookup returned %v", err)
+	}
+}
+
+func TestApplySessions(t *testing.T) {
+	coverage, _ := contract.ParseCoverage("4pm - 8pm")
+	brunch, _ := contract.ParseClock("11am")
+	first := contract.NewDate(time.Date(2030, 5, 18, 0, 0, 0, 0, time.UTC))
+	details := &contract.Contract{
+		PackageID: "birthday-basic",
+		EventDetails: contract.EventDetails{Sessions: []contract.Session{
+			{Name: "Party", Date: first, CoverageTime: coverage, Venue: "Home"},
+			{Name: "Brunch", Date: first.AddDays(1), CoverageTime: contract.Coverage{Start: brunch}, Venue: "Cafe"},
+		}},
+	}
+	details.EventDetails.UseFirstSession()
+	if err := Apply(details); err != nil {
+		t.Fatalf("Apply : %v", err)
+	}
+
+	// Sessions without an end are covered for the hours of the package, those with one keep it
+	sessions := details.EventDetails.Sessions
+	if sessions[0].CoverageTime.End.String() != "8:00 PM" || sessions[1].CoverageTime.End.String() != "2:00 PM" {
+		t.Errorf("sessions end at %s and %s", sessions[0].CoverageTime.End, sessions[1].CoverageTime.End)
+	}
+
+	// Deliverables are counted from the last day of the event
+	if deliverables := details.DeliverableDetails; len(deliverables) != 1 || deliverables[0].DeliveryDate != first.AddDays(22) {
+		t.Errorf("deliverables = %+v", deliverables)
+	}
+}
@@ -1,5 +1,25 @@
 package contract
 
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/money"
+)
+
+// EventDateLayout is the layout event dates are normalized to for storage and filtering.
+const EventDateLayout = "2006-01-02"
+
+var eventDateLayouts = []string{
+	EventDateLayout,
+	"01/02/2006",
+	"1/2/2006",
+	"January 2, 2006",
+	"Jan 2, 2006",
+}
+
 type ClientDetails struct {
 	ClientName  string `json:"clientName"`
 	ClientEmail string `json:"clientEmail"`
@@ -9,26 +29,255 @@ type Deliverable struct {
 	Description  string `json:"description"`
 	Quantity     string `json:"quantity"`
 	Mode         string `json:"mode"`
-	DeliveryDate string `json:"deliveryDate"`
+	DeliveryDate Date   `json:"deliveryDate"`
+	// Session names the session the deliverable comes from, the whole event when empty.
+	Session string `json:"session,omitempty"`
 }
 
 type EventDetails struct {
-	EventName         string `json:"eventName"`
-	EventDate         string `json:"eventDate"`
-	EventCoverageTime string `json:"eventCoverageTime"`
-	EventVenue        string `json:"eventVenue"`
+	EventName         string   `json:"eventName"`
+	EventDate         Date     `json:"eventDate"`
+	EventCoverageTime Coverage `json:"eventCoverageTime"`
+	EventVenue        string   `json:"eventVenue"`
+	// EventType decides which clauses of the terms apply to the contract.
+	EventType EventType `json:"eventType,omitempty"`
+	// Sessions split events that run over several days or venues, such as the mehendi, sangeet, ceremony and
+	// reception of a wedding. The event date, coverage time and venue are those of the first session.
+	Sessions []Session `json:"sessions,omitempty"`
+}
+
+// Session is one part of an event, covered on its own date, time and venue.
+type Session struct {
+	Name         string   `json:"name"`
+	Date         Date     `json:"date"`
+	CoverageTime Coverage `json:"coverageTime"`
+	Venue        string   `json:"venue"`
+}
+
+// AllSessions lists the sessions of the event, a single session named after the event when it is not split.
+func (details EventDetails) AllSessions() []Session {
+	if len(details.Sessions) > 0 {
+		return details.Sessions
+	}
+	return []Session{{
+		Name:         details.EventName,
+		Date:         details.EventDate,
+		CoverageTime: details.EventCoverageTime,
+		Venue:        details.EventVenue,
+	}}
+}
+
+// Session returns the session with the given name.
+func (details EventDetails) Session(name string) (Session, bool) {
+	for _, session := range details.AllSessions() {
+		if strings.EqualFold(session.Name, name) {
+			return session, true
+		}
+	}
+	return Session{}, false
+}
+
+// LastDate is the date of the last session, the event date when the event is not split.
+func (details EventDetails) LastDate() Date {
+	sessions := details.AllSessions()
+	return sessions[len(sessions)-1].Date
+}
+
+// UseFirstSession fills in the event date, coverage time and venue from the first session of a split event.
+func (details *EventDetails) UseFirstSession() {
+	if len(details.Sessions) == 0 {
+		return
+	}
+
+	first := details.Sessions[0]
+	details.EventDate = first.Date
+	details.EventCoverageTime = first.CoverageTime
+	if details.EventVenue == "" {
+		details.EventVenue = first.Venue
+	}
+}
+
+// ParsedEventDate returns the event date as midnight UTC, if it could be read.
+func (details EventDetails) ParsedEventDate() (time.Time, bool) {
+	return details.EventDate.Time(), details.EventDate.Valid()
+}
+
+// Starts is when coverage of the event starts in the event's time zone, if the date and start time are known.
+func (details EventDetails) Starts() (time.Time, bool) {
+	coverage := details.EventCoverageTime
+	if !details.EventDate.Valid() || !coverage.Start.Valid() {
+		return time.Time{}, false
+	}
+	return coverage.Start.On(details.EventDate, coverage.Location()), true
+}
+
+func parseDate(value string) (time.Time, bool) {
+	for _, layout := range eventDateLayouts {
+		date, err := time.Parse(layout, strings.TrimSpace(value))
+		if err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
 }
 
 type PaymentDetails struct {
-	TotalAmount        int64  `json:"totalAmount"`
-	AdvancePaid        int64  `json:"advancePaid,omitempty"`
-	AdvancePaymentMode string `json:"advancePaymentMode,omitempty"`
-	PerHourExtra       int64  `json:"perHourExtra"`
+	// Currency is what every amount of the contract is in, US dollars when empty.
+	Currency           string      `json:"currency,omitempty"`
+	TotalAmount        money.Money `json:"totalAmount"`
+	AdvancePaid        money.Money `json:"advancePaid,omitempty"`
+	AdvancePaymentMode string      `json:"advancePaymentMode,omitempty"`
+	PerHourExtra       money.Money `json:"perHourExtra"`
+	// PaymentPolicy names the pricing policy the booking fee is worked out with, the standard one when empty.
+	PaymentPolicy string `json:"paymentPolicy,omitempty"`
+	// Discount is taken off TotalAmount, so the contract still shows the original price.
+	Discount *Discount `json:"discount,omitempty"`
+	// LineItems are charged on top of TotalAmount, such as prints, travel fees or rush editing.
+	LineItems []LineItem `json:"lineItems,omitempty"`
+	// Installments replace the payment policy when the contract is billed in installments.
+	Installments []Installment `json:"installments,omitempty"`
+}
+
+// Discount is a percent or fixed amount off the contract's services, either given by hand with a reason or from a
+// promo code, in which case the promo code decides the rest.
+type Discount struct {
+	Code    string      `json:"code,omitempty"`
+	Percent float64     `json:"percent,omitempty"`
+	Amount  money.Money `json:"amount,omitempty"`
+	Reason  string      `json:"reason,omitempty"`
+}
+
+// LineItem is a charge on top of the services, taxed according to its category.
+type LineItem struct {
+	Description string      `json:"description"`
+	Amount      money.Money `json:"amount"`
+	// TaxCategory names the tax rate that applies, such as goods for prints and albums or fee for travel.
+	TaxCategory string `json:"taxCategory"`
+}
+
+// UnmarshalJSON puts amounts sent as plain numbers in the contract's currency.
+func (details *PaymentDetails) UnmarshalJSON(data []byte) error {
+	type plain PaymentDetails
+	if err := json.Unmarshal(data, (*plain)(details)); err != nil {
+		return err
+	}
+
+	if details.Currency == "" {
+		details.Currency = details.TotalAmount.Or(money.DefaultCurrency).Currency
+	}
+	details.TotalAmount = details.TotalAmount.Or(details.Currency)
+	details.AdvancePaid = details.AdvancePaid.Or(details.Currency)
+	details.PerHourExtra = details.PerHourExtra.Or(details.Currency)
+	if details.Discount != nil {
+		details.Discount.Amount = details.Discount.Amount.Or(details.Currency)
+	}
+	for i := range details.LineItems {
+		details.LineItems[i].Amount = details.LineItems[i].Amount.Or(details.Currency)
+	}
+	for i := range details.Installments {
+		details.Installments[i].Amount = details.Installments[i].Amount.Or(details.Currency)
+	}
+
+	return nil
+}
+
+// Amounts lists every amount of the payment details, for checking they share the contract's currency.
+func (details PaymentDetails) Amounts() []money.Money {
+	amounts := []money.Money{details.TotalAmount, details.AdvancePaid, details.PerHourExtra}
+	if details.Discount != nil {
+		amounts = append(amounts, details.Discount.Amount)
+	}
+	for _, item := range details.LineItems {
+		amounts = append(amounts, item.Amount)
+	}
+	for _, installment := range details.Installments {
+		amounts = append(amounts, installment.Amount)
+	}
+	return amounts
+}
+
+// InstallmentStatus is whether an installment has been paid.
+type InstallmentStatus string
+
+const (
+	InstallmentPending InstallmentStatus = "pending"
+	InstallmentPaid    InstallmentStatus = "paid"
+)
+
+// Installment is one of the payments a contract is billed in, either a fixed amount or a percent of the total.
+type Installment struct {
+	Description string            `json:"description"`
+	Amount      money.Money       `json:"amount,omitempty"`
+	Percent     float64           `json:"percent,omitempty"`
+	DueDate     string            `json:"dueDate"`
+	Modes       []string          `json:"modes"`
+	Status      InstallmentStatus `json:"status,omitempty"`
+}
+
+// ParsedDueDate reads DueDate in any of the formats event dates are accepted in.
+func (installment Installment) ParsedDueDate() (time.Time, bool) {
+	return parseDate(installment.DueDate)
+}
+
+// InstallmentAmounts resolves the installments of the total to amounts in order. Percent installments are rounded
+// to the cent, with the last of them absorbing the rounding so the installments add up to the total exactly.
+func (details PaymentDetails) InstallmentAmounts(total money.Money) ([]money.Money, error) {
+	amounts := make([]money.Money, len(details.Installments))
+	lastPercent := -1
+	sum := money.New(0, details.Currency)
+	fixed := money.New(0, details.Currency)
+	var basisPoints int64
+	for i, installment := range details.Installments {
+		switch {
+		case installment.Amount.IsNegative() || installment.Percent < 0:
+			return nil, fmt.Errorf("installment %d cannot be negative", i+1)
+		case !installment.Amount.IsZero() && installment.Percent != 0:
+			return nil, fmt.Errorf("installment %d should have either an amount or a percent", i+1)
+		case !installment.Amount.IsZero():
+			amounts[i] = installment.Amount
+			fixed = fixed.Add(installment.Amount)
+		case installment.Percent != 0:
+			share := money.BasisPoints(installment.Percent)
+			amounts[i] = total.Share(share)
+			basisPoints += share
+			lastPercent = i
+		default:
+			return nil, fmt.Errorf("installment %d needs an amount or a percent", i+1)
+		}
+		sum = sum.Add(amounts[i])
+	}
+
+	// The fixed installments and the percentages have to cover the total exactly, before any rounding
+	if fixed.Amount*10000+total.Amount*basisPoints != total.Amount*10000 {
+		return nil, fmt.Errorf("installments add up to %s instead of the total amount of %s", sum, total)
+	}
+	if lastPercent >= 0 {
+		amounts[lastPercent] = amounts[lastPercent].Add(total.Sub(sum))
+	}
+
+	return amounts, nil
 }
 
 type Contract struct {
+	// PackageID names the catalog package the contract fills in what it leaves out from.
+	PackageID          string         `json:"packageId,omitempty"`
 	ClientDetails      ClientDetails  `json:"clientDetails"`
 	EventDetails       EventDetails   `json:"eventDetails"`
 	PaymentDetails     PaymentDetails `json:"paymentDetails"`
 	DeliverableDetails []Deliverable  `json:"deliverableDetails"`
+	// ClauseOverrides replace the standard clauses of the terms with the same key, for conditions negotiated with
+	// the client. Only clauses the terms mark as overridable can be replaced.
+	ClauseOverrides []CustomClause `json:"clauseOverrides,omitempty"`
+	// AdditionalClauses are added after the standard clauses of the terms.
+	AdditionalClauses []CustomClause `json:"additionalClauses,omitempty"`
+}
+
+// CustomClause is a clause of the terms written for one contract. Its body is used as it is written, it is not a
+// template like the standard clauses.
+type CustomClause struct {
+	// Key names the standard clause an override replaces, additional clauses leave it empty.
+	Key string `json:"key,omitempty"`
+	// Heading of an override is the heading of the clause it replaces when empty.
+	Heading string `json:"heading,omitempty"`
+	Body    string `json:"body"`
 }
@@ -0,0 +1,317 @@
+package contract
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	// Time zones are looked up by name, so the database is embedded for hosts that do not have one
+	_ "time/tzdata"
+)
+
+// DefaultTimeZone is the time zone of coverage times that do not name one, where the studio is based.
+const DefaultTimeZone = "America/New_York"
+
+// dateDisplayLayout is how dates are written out on documents.
+const dateDisplayLayout = "January 2, 2006"
+
+var clockLayouts = []string{"15:04", "3:04pm", "3pm"}
+
+const minutesPerDay = 24 * 60
+
+// maxOvernightCoverage is how long coverage running past midnight may last without being marked as ending the next
+// day, so a mistyped 8pm - 4pm is not read as 20 hours of coverage.
+const maxOvernightCoverage = 8 * time.Hour
+
+// nextDayMarker follows the end of a coverage time that is on the next day, as in 8pm - 6am +1.
+const nextDayMarker = "+1"
+
+// Date is a calendar day, written as 2006-01-02. Dates of contracts issued before dates were validated that cannot
+// be read keep their text, so those contracts can still be loaded and shown.
+type Date struct {
+	day  time.Time
+	text string
+}
+
+// NewDate returns the calendar day of the given time in its location.
+func NewDate(t time.Time) Date {
+	return Date{day: time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+// Time is midnight UTC of the day, the zero time when the date is not valid.
+func (date Date) Time() time.Time {
+	return date.day
+}
+
+func (date Date) IsZero() bool {
+	return date.day.IsZero() && date.text == ""
+}
+
+// Valid is whether the date could be read.
+func (date Date) Valid() bool {
+	return !date.day.IsZero()
+}
+
+func (date Date) After(other Date) bool {
+	return date.day.After(other.day)
+}
+
+// AddDays returns the date the given number of days later.
+func (date Date) AddDays(days int) Date {
+	if !date.Valid() {
+		return date
+	}
+	return Date{day: date.day.AddDate(0, 0, days)}
+}
+
+// String writes the date out for documents, as in May 20, 2030.
+func (date Date) String() string {
+	if !date.Valid() {
+		return date.text
+	}
+	return date.day.Format(dateDisplayLayout)
+}
+
+func (date Date) MarshalJSON() ([]byte, error) {
+	if !date.Valid() {
+		return json.Marshal(date.text)
+	}
+	return json.Marshal(date.day.Format(EventDateLayout))
+}
+
+func (date *Date) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("dates should be written as %s", EventDateLayout)
+	}
+
+	day, ok := parseDate(value)
+	if !ok {
+		*date = Date{text: value}
+		return nil
+	}
+	*date = Date{day: day}
+	return nil
+}
+
+// Clock is a time of day to the minute, written as 15:04.
+type Clock struct {
+	minutes int
+	valid   bool
+}
+
+// ParseClock reads a time of day written as 16:30, 4:30pm or 4pm.
+func ParseClock(value string) (Clock, error) {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(value), " ", ""))
+	normalized = strings.NewReplacer("a.m.", "am", "p.m.", "pm").Replace(normalized)
+	for _, layout := range clockLayouts {
+		parsed, err := time.Parse(layout, normalized)
+		if err == nil {
+			return Clock{minutes: parsed.Hour()*60 + parsed.Minute(), valid: true}, nil
+		}
+	}
+	return Clock{}, fmt.Errorf("%q is not a time of day, times should be written as 15:04", value)
+}
+
+// Valid is whether the time of day has been set.
+func (clock Clock) Valid() bool {
+	return clock.valid
+}
+
+// Add returns the time of day the given duration later, going round past midnight.
+func (clock Clock) Add(duration time.Duration) Clock {
+	minutes := (clock.minutes + int(duration/time.Minute)) % minutesPerDay
+	if minutes < 0 {
+		minutes += minutesPerDay
+	}
+	return Clock{minutes: minutes, valid: clock.valid}
+}
+
+// On is the time of day on the given date in the given location.
+func (clock Clock) On(date Date, location *time.Location) time.Time {
+	day := date.Time()
+	return time.Date(day.Year(), day.Month(), day.Day(), clock.minutes/60, clock.minutes%60, 0, 0, location)
+}
+
+// String writes the time of day out for documents, as in 4:30 PM.
+func (clock Clock) String() string {
+	if !clock.valid {
+		return ""
+	}
+	return time.Date(0, 1, 1, clock.minutes/60, clock.minutes%60, 0, 0, time.UTC).Format("3:04 PM")
+}
+
+func (clock Clock) MarshalJSON() ([]byte, error) {
+	if !clock.valid {
+		return json.Marshal("")
+	}
+	return json.Marshal(fmt.Sprintf("%02d:%02d", clock.minutes/60, clock.minutes%60))
+}
+
+func (clock *Clock) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return errors.New("times should be written as 15:04")
+	}
+	if value == "" {
+		*clock = Clock{}
+		return nil
+	}
+
+	parsed, err := ParseClock(value)
+	if err != nil {
+		return err
+	}
+	*clock = parsed
+	return nil
+}
+
+// Coverage is the time of day the team covers an event for, in the time zone of the event. Coverage times of
+// contracts issued before they were validated that cannot be read keep their text, like dates do.
+type Coverage struct {
+	Start Clock `json:"start"`
+	End   Clock `json:"end"`
+	// NextDay marks coverage that ends on the day after it starts. Coverage ending past midnight is only taken to
+	// be on the next day without it for up to maxOvernightCoverage.
+	NextDay bool `json:"nextDay,omitempty"`
+	// TimeZone is the IANA name of the event's time zone, DefaultTimeZone when empty.
+	TimeZone string `json:"timeZone,omitempty"`
+	text     string
+}
+
+// ParseCoverage reads a coverage time written as a range, as in 4pm - 8pm or 16:00 to 20:00. An end on the next day
+// is followed by +1, as in 8pm - 6am +1.
+func ParseCoverage(value string) (Coverage, error) {
+	var parts []string
+	for _, separator := range []string{" to ", "-", "–"} {
+		if parts = strings.SplitN(value, separator, 2); len(parts) == 2 {
+			break
+		}
+	}
+	if len(parts) != 2 {
+		return Coverage{}, fmt.Errorf("%q is not a coverage time, it should have a start and an end time", value)
+	}
+
+	start, err := ParseClock(parts[0])
+	if err != nil {
+		return Coverage{}, err
+	}
+	endText := strings.TrimSpace(parts[1])
+	nextDay := strings.HasSuffix(endText, nextDayMarker)
+	end, err := ParseClock(strings.TrimSuffix(endText, nextDayMarker))
+	if err != nil {
+		return Coverage{}, err
+	}
+	return Coverage{Start: start, End: end, NextDay: nextDay}, nil
+}
+
+// Validate checks the coverage has a start and an end after it in a known time zone. An end before the start is on
+// the next day when the coverage is marked so, or when it lasts no longer than maxOvernightCoverage, as in 7pm - 1am.
+func (coverage Coverage) Validate() error {
+	if coverage.text != "" {
+		_, err := ParseCoverage(coverage.text)
+		return err
+	}
+	if !coverage.Start.Valid() || !coverage.End.Valid() {
+		return errors.New("coverage start and end times are required")
+	}
+	if coverage.Duration() == 0 {
+		return errors.New("coverage end time should be different from the start time")
+	}
+	overnight := coverage.End.minutes < coverage.Start.minutes
+	if coverage.NextDay && !overnight {
+		return errors.New("coverage ending the next day should end before the time it starts, it would last longer than a day")
+	}
+	if !coverage.NextDay && overnight && coverage.Duration() > maxOvernightCoverage {
+		return fmt.Errorf("coverage end time should be after the start time, coverage running past midnight for more than %s should be marked as ending the next day with %s", maxOvernightCoverage, nextDayMarker)
+	}
+	if _, err := time.LoadLocation(coverage.timeZone()); err != nil {
+		return fmt.Errorf("time zone %s is not known", coverage.TimeZone)
+	}
+	return nil
+}
+
+// Duration is how long the coverage lasts, running into the next day when it ends before it starts.
+func (coverage Coverage) Duration() time.Duration {
+	minutes := coverage.End.minutes - coverage.Start.minutes
+	if minutes < 0 {
+		minutes += minutesPerDay
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// SetDuration ends the coverage the given duration after it starts, marking it as ending the next day when it runs
+// past midnight.
+func (coverage *Coverage) SetDuration(duration time.Duration) {
+	coverage.End = coverage.Start.Add(duration)
+	coverage.NextDay = coverage.End.minutes < coverage.Start.minutes
+}
+
+// IsZero is whether no coverage time has been given.
+func (coverage Coverage) IsZero() bool {
+	return !coverage.Start.Valid() && !coverage.End.Valid() && coverage.text == ""
+}
+
+// Location is the time zone of the event, UTC when it is not known.
+func (coverage Coverage) Location() *time.Location {
+	location, err := time.LoadLocation(coverage.timeZone())
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+func (coverage Coverage) timeZone() string {
+	if coverage.TimeZone == "" {
+		return DefaultTimeZone
+	}
+	return coverage.TimeZone
+}
+
+// Format writes the coverage out for documents with the time zone in effect on the event date, as in
+// 4:00 PM - 8:00 PM EDT.
+func (coverage Coverage) Format(date Date) string {
+	if coverage.text != "" {
+		return coverage.text
+	}
+	if coverage.IsZero() {
+		return ""
+	}
+
+	label := fmt.Sprintf("%s - %s", coverage.Start, coverage.End)
+	if coverage.NextDay {
+		label += " (next day)"
+	}
+	if date.Valid() {
+		zone, _ := coverage.Start.On(date, coverage.Location()).Zone()
+		label += " " + zone
+	}
+	return label
+}
+
+func (coverage Coverage) MarshalJSON() ([]byte, error) {
+	if coverage.text != "" {
+		return json.Marshal(coverage.text)
+	}
+	type plain Coverage
+	return json.Marshal(plain(coverage))
+}
+
+// UnmarshalJSON accepts a {"start", "end", "timeZone"} object, or a range as written before coverage times had
+// a structure.
+func (coverage *Coverage) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err == nil {
+		parsed, err := ParseCoverage(value)
+		if err != nil {
+			*coverage = Coverage{text: value}
+			return nil
+		}
+		*coverage = parsed
+		return nil
+	}
+
+	type plain Coverage
+	return json.Unmarshal(data, (*plain)(coverage))
+}
@@ -0,0 +1,107 @@
+package contract
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseCoverage(t *testing.T) {
+	tests := []struct {
+		value    string
+		want     string
+		duration time.Duration
+	}{
+		{"4pm - 8pm", "4:00 PM - 8:00 PM", 4 * time.Hour},
+		{"16:30 to 20:00", "4:30 PM - 8:00 PM", 3*time.Hour + 30*time.Minute},
+		{"7 p.m. – 1 a.m.", "7:00 PM - 1:00 AM", 6 * time.Hour},
+		{"11pm - 12:30am", "11:00 PM - 12:30 AM", 90 * time.Minute},
+		{"8pm - 6am +1", "8:00 PM - 6:00 AM (next day)", 10 * time.Hour},
+		{"10am - 9am+1", "10:00 AM - 9:00 AM (next day)", 23 * time.Hour},
+	}
+
+	for _, test := range tests {
+		coverage, err := ParseCoverage(test.value)
+		if err != nil {
+			t.Errorf("ParseCoverage(%q) : %v", test.value, err)
+			continue
+		}
+		if err := coverage.Validate(); err != nil {
+			t.Errorf("%q does not validate : %v", test.value, err)
+		}
+		if got := coverage.Format(Date{}); got != test.want {
+			t.Errorf("%q is written as %q, want %q", test.value, got, test.want)
+		}
+		if got := coverage.Duration(); got != test.duration {
+			t.Errorf("%q lasts %s, want %s", test.value, got, test.duration)
+		}
+	}
+
+	for _, value := range []string{"4pm", "4pm - later", "noon - 8pm"} {
+		if _, err := ParseCoverage(value); err == nil {
+			t.Errorf("ParseCoverage(%q) did not fail", value)
+		}
+	}
+
+	// Coverage ending before it starts is only read as running past midnight for a few hours, unless marked
+	for _, value := range []string{"4pm - 4pm", "8pm - 4pm", "11am - 2am", "4pm - 8pm +1"} {
+		coverage, err := ParseCoverage(value)
+		if err != nil {
+			t.Errorf("ParseCoverage(%q) : %v", value, err)
+			continue
+		}
+		if err := coverage.Validate(); err == nil {
+			t.Errorf("%q validates", value)
+		}
+	}
+}
+
+func TestCoverageTimeZone(t *testing.T) {
+	coverage, _ := ParseCoverage("4pm - 8pm")
+	summer := NewDate(time.Date(2030, 7, 1, 0, 0, 0, 0, time.UTC))
+	winter := NewDate(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	if got := coverage.Format(summer); got != "4:00 PM - 8:00 PM EDT" {
+		t.Errorf("summer coverage = %q", got)
+	}
+	if got := coverage.Format(winter); got != "4:00 PM - 8:00 PM EST" {
+		t.Errorf("winter coverage = %q", got)
+	}
+
+	coverage.TimeZone = "Mars/Olympus"
+	if err := coverage.Validate(); err == nil {
+		t.Error("coverage in an unknown time zone validates")
+	}
+}
+
+func TestClockAdd(t *testing.T) {
+	start, _ := ParseClock("21:00")
+	if got := start.Add(4 * time.Hour).String(); got != "1:00 AM" {
+		t.Errorf("21:00 plus 4 hours = %s", got)
+	}
+	if got := start.Add(-22 * time.Hour).String(); got != "11:00 PM" {
+		t.Errorf("21:00 less 22 hours = %s", got)
+	}
+}
+
+func TestDateJSON(t *testing.T) {
+	var details EventDetails
+	if err := json.Unmarshal([]byte(`{"eventDate": "2030-05-20", "eventCoverageTime": "4pm - 8pm"}`), &details); err != nil {
+		t.Fatalf("Unmarshal : %v", err)
+	}
+	if details.EventDate.String() != "May 20, 2030" || details.EventCoverageTime.Start.String() != "4:00 PM" {
+		t.Errorf("event on %s at %s", details.EventDate, details.EventCoverageTime.Start)
+	}
+
+	// Contracts issued before dates and times were validated keep what they were written with
+	var legacy EventDetails
+	if err := json.Unmarshal([]byte(`{"eventDate": "sometime in May", "eventCoverageTime": "evening"}`), &legacy); err != nil {
+		t.Fatalf("Unmarshal : %v", err)
+	}
+	data, _ := json.Marshal(legacy)
+	var written map[string]interface{}
+	json.Unmarshal(data, &written)
+	if written["eventDate"] != "sometime in May" || written["eventCoverageTime"] != "evening" {
+		t.Errorf("legacy event details were written as %s", data)
+	}
+}
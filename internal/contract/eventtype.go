@@ -0,0 +1,38 @@
+package contract
+
+import (
+	"errors"
+	"fmt"
+)
+
+// EventType is the kind of event a contract covers, which decides the clauses of its terms.
+type EventType string
+
+const (
+	// EventTypeWedding contracts cover weddings, with albums and longer editing.
+	EventTypeWedding EventType = "wedding"
+	// EventTypeEvent contracts cover parties and other events, and contracts that do not name a type.
+	EventTypeEvent EventType = "event"
+	// EventTypeCorporate contracts cover shoots for businesses, who license the footage and are invoiced.
+	EventTypeCorporate EventType = "corporate"
+	// EventTypeSmall contracts cover birthdays and small gatherings under the short form terms.
+	EventTypeSmall EventType = "small"
+)
+
+var ErrInvalidEventType = errors.New("invalid event type")
+
+// EventTypes lists every event type.
+var EventTypes = []EventType{EventTypeWedding, EventTypeEvent, EventTypeCorporate, EventTypeSmall}
+
+// ParseEventType checks the event type is known, an empty one is an event.
+func ParseEventType(value string) (EventType, error) {
+	if value == "" {
+		return EventTypeEvent, nil
+	}
+	for _, eventType := range EventTypes {
+		if EventType(value) == eventType {
+			return eventType, nil
+		}
+	}
+	return "", fmt.Errorf("%w : %s", ErrInvalidEventType, value)
+}
@@ -0,0 +1,25 @@
+package contract
+
+import (
+	"time"
+
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/money"
+)
+
+// Invoice is the invoice of a contract as it was issued under InvoiceNumber. It is kept with the contract so the
+// invoice reads the same every time it is sent, payments received after it was issued are confirmed by receipts.
+type Invoice struct {
+	IssuedAt   time.Time     `json:"issuedAt"`
+	Lines      []InvoiceLine `json:"lines"`
+	Total      money.Money   `json:"total"`
+	Received   money.Money   `json:"received"`
+	BalanceDue money.Money   `json:"balanceDue"`
+}
+
+// InvoiceLine is a payment of the schedule as it stood when the invoice was issued.
+type InvoiceLine struct {
+	Description string      `json:"description"`
+	Due         string      `json:"due"`
+	Amount      money.Money `json:"amount"`
+	Received    money.Money `json:"received"`
+}
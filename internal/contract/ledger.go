@@ -0,0 +1,86 @@
+package contract
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/money"
+)
+
+var ErrInvalidPayment = errors.New("invalid payment")
+
+// LedgerEntry is a payment received from the client against a contract.
+type LedgerEntry struct {
+	ID     string      `json:"id"`
+	Amount money.Money `json:"amount"`
+	Mode   string      `json:"mode"`
+	// Reference is the Zelle confirmation, cheque number or similar, when there is one.
+	Reference  string    `json:"reference,omitempty"`
+	ReceivedAt time.Time `json:"receivedAt"`
+	RecordedBy string    `json:"recordedBy"`
+	RecordedAt time.Time `json:"recordedAt"`
+	// ReceiptNumber is assigned the first time a receipt is issued for the payment.
+	ReceiptNumber string `json:"receiptNumber,omitempty"`
+}
+
+// RecordPayment adds a received payment to the contract's ledger.
+func (record *Record) RecordPayment(entry LedgerEntry) (*LedgerEntry, error) {
+	if record.Status == StatusCancelled {
+		return nil, fmt.Errorf("%w : cannot record a payment against a cancelled contract", ErrInvalidTransition)
+	}
+
+	entry.Amount = entry.Amount.Or(record.Contract.PaymentDetails.Currency)
+	if entry.Amount.Amount <= 0 {
+		return nil, fmt.Errorf("%w : amount should be greater than zero", ErrInvalidPayment)
+	}
+	if entry.Amount.Currency != record.Contract.PaymentDetails.Currency {
+		return nil, fmt.Errorf("%w : payment is in %s but the contract is in %s", ErrInvalidPayment, entry.Amount.Currency, record.Contract.PaymentDetails.Currency)
+	}
+
+	entry.Mode = strings.TrimSpace(entry.Mode)
+	if entry.Mode == "" {
+		return nil, fmt.Errorf("%w : payment mode is required", ErrInvalidPayment)
+	}
+
+	entry.RecordedBy = strings.TrimSpace(entry.RecordedBy)
+	if entry.RecordedBy == "" {
+		return nil, fmt.Errorf("%w : recorded by is required", ErrInvalidPayment)
+	}
+
+	now := time.Now().UTC()
+	if entry.ReceivedAt.IsZero() {
+		entry.ReceivedAt = now
+	}
+	if entry.ReceivedAt.After(now.Add(time.Minute)) {
+		return nil, fmt.Errorf("%w : payments cannot be received in the future", ErrInvalidPayment)
+	}
+
+	entry.ID = uuid.NewString()
+	entry.ReceivedAt = entry.ReceivedAt.UTC()
+	entry.RecordedAt = now
+	record.Ledger = append(record.Ledger, entry)
+
+	return &record.Ledger[len(record.Ledger)-1], nil
+}
+
+// Received is the total of the payments in the ledger.
+func (record *Record) Received() money.Money {
+	received := money.New(0, record.Contract.PaymentDetails.Currency)
+	for _, entry := range record.Ledger {
+		received = received.Add(entry.Amount)
+	}
+	return received
+}
+
+// LedgerEntry returns the payment with the given ID from the contract's ledger.
+func (record *Record) LedgerEntry(id string) (*LedgerEntry, bool) {
+	for i := range record.Ledger {
+		if record.Ledger[i].ID == id {
+			return &record.Ledger[i], true
+		}
+	}
+	return nil, false
+}
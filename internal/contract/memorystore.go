@@ -0,0 +1,155 @@
+package contract
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// MemoryStore is a ContractStore that only keeps contracts for the lifetime of the process.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	records   map[string]*Record
+	sequences map[string]int64
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: map[string]*Record{}, sequences: map[string]int64{}}
+}
+
+func (store *MemoryStore) Create(record *Record) error {
+	prepareNewRecord(record)
+
+	clone, err := cloneRecord(record)
+	if err != nil {
+		return err
+	}
+
+	clone.ShownDocument, clone.SignedDocument = record.ShownDocument, record.SignedDocument
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.records[clone.ID] = clone
+
+	return nil
+}
+
+func (store *MemoryStore) Update(record *Record) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	stored, ok := store.records[record.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if stored.Version != record.Version {
+		return fmt.Errorf("%w : contract %s", ErrConflict, record.ID)
+	}
+
+	record.Version++
+	clone, err := cloneRecord(record)
+	if err != nil {
+		record.Version--
+		return err
+	}
+	clone.ShownDocument, clone.SignedDocument = stored.ShownDocument, stored.SignedDocument
+	if record.ShownDocument != nil {
+		clone.ShownDocument = record.ShownDocument
+	}
+	if record.SignedDocument != nil {
+		clone.SignedDocument = record.SignedDocument
+	}
+	store.records[clone.ID] = clone
+
+	return nil
+}
+
+func (store *MemoryStore) NextNumber(sequence string) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.sequences[sequence]++
+	return store.sequences[sequence], nil
+}
+
+func (store *MemoryStore) RedeemPromoCode(code string, maxUses int64) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	sequence := promoCodeSequence(code)
+	if maxUses > 0 && store.sequences[sequence] >= maxUses {
+		return 0, fmt.Errorf("%w : %s", ErrPromoCodeUsedUp, code)
+	}
+	store.sequences[sequence]++
+	return store.sequences[sequence], nil
+}
+
+func (store *MemoryStore) ReleasePromoCode(code string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if sequence := promoCodeSequence(code); store.sequences[sequence] > 0 {
+		store.sequences[sequence]--
+	}
+	return nil
+}
+
+func (store *MemoryStore) Get(id string) (*Record, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	record, ok := store.records[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	clone, err := cloneRecord(record)
+	if err != nil {
+		return nil, err
+	}
+	clone.ShownDocument, clone.SignedDocument = record.ShownDocument, record.SignedDocument
+
+	return clone, nil
+}
+
+func (store *MemoryStore) List(filter Filter) ([]*Record, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	var matched []*Record
+	for _, record := range store.records {
+		if filter.matches(record) {
+			matched = append(matched, record)
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].CreatedAt.After(matched[j].CreatedAt)
+	})
+
+	if filter.Offset > 0 {
+		if filter.Offset >= len(matched) {
+			matched = nil
+		} else {
+			matched = matched[filter.Offset:]
+		}
+	}
+	if filter.Limit > 0 && filter.Limit < len(matched) {
+		matched = matched[:filter.Limit]
+	}
+
+	records := make([]*Record, 0, len(matched))
+	for _, record := range matched {
+		clone, err := cloneRecord(record)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, clone)
+	}
+
+	return records, nil
+}
+
+func (store *MemoryStore) Close() error {
+	return nil
+}
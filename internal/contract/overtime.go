@@ -0,0 +1,58 @@
+package contract
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/money"
+)
+
+var ErrInvalidOvertime = errors.New("invalid overtime")
+
+// Overtime is coverage beyond a session's coverage time, charged at the contract's per hour extra.
+type Overtime struct {
+	ID      string  `json:"id"`
+	Session string  `json:"session"`
+	Hours   float64 `json:"hours"`
+	// Amount is Hours at the per hour extra of the contract when the overtime was recorded.
+	Amount     money.Money `json:"amount"`
+	Note       string      `json:"note,omitempty"`
+	RecordedBy string      `json:"recordedBy"`
+	RecordedAt time.Time   `json:"recordedAt"`
+}
+
+// RecordOvertime charges the hours worked past the coverage time of the named session.
+func (record *Record) RecordOvertime(overtime Overtime) (*Overtime, error) {
+	if record.Status == StatusCancelled {
+		return nil, fmt.Errorf("%w : cannot record overtime for a cancelled contract", ErrInvalidTransition)
+	}
+
+	session, ok := record.Contract.EventDetails.Session(overtime.Session)
+	if !ok {
+		return nil, fmt.Errorf("%w : the event has no session named %s", ErrInvalidOvertime, overtime.Session)
+	}
+	overtime.Session = session.Name
+
+	if overtime.Hours <= 0 || overtime.Hours > 24 {
+		return nil, fmt.Errorf("%w : hours should be more than 0 and at most 24", ErrInvalidOvertime)
+	}
+
+	overtime.RecordedBy = strings.TrimSpace(overtime.RecordedBy)
+	if overtime.RecordedBy == "" {
+		return nil, fmt.Errorf("%w : recorded by is required", ErrInvalidOvertime)
+	}
+
+	perHourExtra := record.Contract.PaymentDetails.PerHourExtra
+	if perHourExtra.IsZero() {
+		return nil, fmt.Errorf("%w : the contract does not charge for extra hours", ErrInvalidOvertime)
+	}
+	overtime.Amount = perHourExtra.Share(money.BasisPoints(overtime.Hours * 100))
+	overtime.ID = uuid.NewString()
+	overtime.RecordedAt = time.Now().UTC()
+	record.Overtime = append(record.Overtime, overtime)
+
+	return &record.Overtime[len(record.Overtime)-1], nil
+}
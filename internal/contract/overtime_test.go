@@ -0,0 +1,45 @@
+package contract
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRecordOvertime(t *testing.T) {
+	record := NewRecord(testContract("Priya Raman", time.Now()))
+	reception, _ := ParseCoverage("6pm - 11pm")
+	record.Contract.EventDetails.Sessions = []Session{
+		{Name: "Mehendi", Date: NewDate(time.Now()), CoverageTime: reception, Venue: "Home"},
+		{Name: "Reception", Date: NewDate(time.Now().AddDate(0, 0, 1)), CoverageTime: reception, Venue: "Hall"},
+	}
+
+	overtime, err := record.RecordOvertime(Overtime{Session: "reception", Hours: 1.5, RecordedBy: " Anita "})
+	if err != nil {
+		t.Fatalf("RecordOvertime : %v", err)
+	}
+	// 1.5 hours at 150 per hour, charged against the session as it is named on the contract
+	if overtime.Session != "Reception" || overtime.Amount.Amount != 22500 || overtime.RecordedBy != "Anita" || overtime.ID == "" {
+		t.Errorf("overtime = %+v", overtime)
+	}
+	if len(record.Overtime) != 1 {
+		t.Errorf("%d overtime entries recorded", len(record.Overtime))
+	}
+
+	tests := map[string]Overtime{
+		"unknown session": {Session: "Sangeet", Hours: 1, RecordedBy: "Anita"},
+		"no hours":        {Session: "Mehendi", Hours: 0, RecordedBy: "Anita"},
+		"over a day":      {Session: "Mehendi", Hours: 25, RecordedBy: "Anita"},
+		"no recorder":     {Session: "Mehendi", Hours: 1},
+	}
+	for name, overtime := range tests {
+		if _, err := record.RecordOvertime(overtime); !errors.Is(err, ErrInvalidOvertime) {
+			t.Errorf("%s : RecordOvertime returned %v, want ErrInvalidOvertime", name, err)
+		}
+	}
+
+	record.Status = StatusCancelled
+	if _, err := record.RecordOvertime(Overtime{Session: "Mehendi", Hours: 1, RecordedBy: "Anita"}); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("overtime of a cancelled contract returned %v", err)
+	}
+}
@@ -0,0 +1,175 @@
+package contract
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SignatureSource is where a client signed the contract.
+type SignatureSource string
+
+const (
+	SignatureSourceGoogleForm  SignatureSource = "google_form"
+	SignatureSourceSigningPage SignatureSource = "signing_page"
+)
+
+// nameSimilarityThreshold is how close a signed name has to be to the client name to not need a review.
+const nameSimilarityThreshold = 0.8
+
+// Signature is the printed name a client signed a contract with.
+type Signature struct {
+	Name     string          `json:"name"`
+	SignedAt time.Time       `json:"signedAt"`
+	Source   SignatureSource `json:"source"`
+	// Drawing is a base64 encoded PNG of a signature drawn on the signing page.
+	Drawing   string `json:"drawing,omitempty"`
+	IPAddress string `json:"ipAddress,omitempty"`
+	UserAgent string `json:"userAgent,omitempty"`
+	// DocumentHash is the SHA-256 of the contract PDF the client was shown when signing. Contracts signed before the
+	// PDF was kept have the fingerprint of the contract details instead.
+	DocumentHash string `json:"documentHash,omitempty"`
+	// NeedsReview is set when the signed name does not match the client name on the contract.
+	NeedsReview bool `json:"needsReview"`
+}
+
+// Sign records the client's signature and marks the contract as signed.
+// Signatures that do not match the client name are still accepted but flagged for review.
+func (record *Record) Sign(signature Signature) error {
+	signature.NeedsReview = !NamesMatch(signature.Name, record.Contract.ClientDetails.ClientName)
+
+	note := "signed by " + signature.Name
+	if signature.NeedsReview {
+		note += ", signed name does not match the client name"
+	}
+
+	if err := record.Transition(StatusSigned, note); err != nil {
+		return err
+	}
+	record.Signature = &signature
+
+	return nil
+}
+
+// Fingerprint is a SHA-256 of the contract details, used to tell whether a contract changed while a client was
+// reviewing it.
+func (details Contract) Fingerprint() (string, error) {
+	data, err := json.Marshal(details)
+	if err != nil {
+		return "", fmt.Errorf("failed while marshalling contract for its fingerprint with err : %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// DocumentHash is the SHA-256 of a rendered document, printed on the audit certificate of signed contracts.
+func DocumentHash(document []byte) string {
+	sum := sha256.Sum256(document)
+	return hex.EncodeToString(sum[:])
+}
+
+// ShownDocumentHash is the SHA-256 of the PDF the client is shown, the signing page compares it to tell whether the
+// agreement changed while the client was reviewing it. Contracts issued before the PDF was kept have the fingerprint
+// of the contract details instead.
+func (record *Record) ShownDocumentHash() (string, error) {
+	if record.ShownDocument == nil {
+		return record.Contract.Fingerprint()
+	}
+	return DocumentHash(record.ShownDocument), nil
+}
+
+// SigningTokenMatches compares the token from a signing link to the record's in constant time.
+func (record *Record) SigningTokenMatches(token string) bool {
+	return record.SigningToken != "" && subtle.ConstantTimeCompare([]byte(record.SigningToken), []byte(token)) == 1
+}
+
+// NamesMatch reports whether a typed name is close enough to the expected one, ignoring case, punctuation,
+// word order, missing middle names and small typos.
+func NamesMatch(typed string, expected string) bool {
+	typedWords := normalizeName(typed)
+	expectedWords := normalizeName(expected)
+	if len(typedWords) == 0 || len(expectedWords) == 0 {
+		return false
+	}
+
+	if containsAllWords(typedWords, expectedWords) || containsAllWords(expectedWords, typedWords) {
+		return true
+	}
+
+	return nameSimilarity(strings.Join(typedWords, " "), strings.Join(expectedWords, " ")) >= nameSimilarityThreshold
+}
+
+func normalizeName(name string) []string {
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, name)
+	return strings.Fields(cleaned)
+}
+
+// containsAllWords reports whether every word of the shorter name is part of the longer one.
+func containsAllWords(longer []string, shorter []string) bool {
+	if len(shorter) > len(longer) || len(shorter) < 2 && len(longer) > 1 {
+		return false
+	}
+
+	words := map[string]bool{}
+	for _, word := range longer {
+		words[word] = true
+	}
+	for _, word := range shorter {
+		if !words[word] {
+			return false
+		}
+	}
+	return true
+}
+
+// nameSimilarity is one minus the Levenshtein distance between the names relative to the longer one.
+func nameSimilarity(a string, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return 1 - float64(previous[len(rb)])/float64(longest)
+}
+
+func minInt(values ...int) int {
+	min := values[0]
+	for _, value := range values[1:] {
+		if value < min {
+			min = value
+		}
+	}
+	return min
+}
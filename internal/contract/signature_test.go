@@ -0,0 +1,35 @@
+package contract
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSigningTokenMatches(t *testing.T) {
+	record := &Record{}
+	if record.SigningTokenMatches("") {
+		t.Error("a record without a signing link matches an empty token")
+	}
+
+	record.SigningToken = "0123456789abcdef"
+	for token, want := range map[string]bool{"0123456789abcdef": true, "0123456789abcdeF": false, "0123456789": false, "": false} {
+		if got := record.SigningTokenMatches(token); got != want {
+			t.Errorf("SigningTokenMatches(%q) = %v, want %v", token, got, want)
+		}
+	}
+}
+
+func TestSign(t *testing.T) {
+	record := NewRecord(testContract("Priya Raman", time.Now()))
+	if err := record.Sign(Signature{Name: "Priya Raman"}); err == nil {
+		t.Fatal("a draft contract was signed")
+	}
+
+	record.Status = StatusSent
+	if err := record.Sign(Signature{Name: "P. Raman"}); err != nil {
+		t.Fatalf("Sign : %v", err)
+	}
+	if record.Status != StatusSigned || record.Signature == nil || !record.Signature.NeedsReview {
+		t.Errorf("signed contract is %s with signature %+v", record.Status, record.Signature)
+	}
+}
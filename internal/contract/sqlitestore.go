@@ -0,0 +1,334 @@
+package contract
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteMigrations are applied in order, PRAGMA user_version tracks how many have already run.
+var sqliteMigrations = []string{
+	`CREATE TABLE IF NOT EXISTS contracts (
+		id           TEXT PRIMARY KEY,
+		created_at   TEXT NOT NULL,
+		form_id      TEXT NOT NULL,
+		client_email TEXT NOT NULL,
+		event_name   TEXT NOT NULL,
+		event_date   TEXT NOT NULL,
+		data         TEXT NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS contracts_client_email ON contracts (client_email);`,
+	`ALTER TABLE contracts ADD COLUMN status TEXT NOT NULL DEFAULT 'sent';
+	CREATE INDEX IF NOT EXISTS contracts_status ON contracts (status);`,
+	`CREATE TABLE IF NOT EXISTS sequences (
+		name  TEXT PRIMARY KEY,
+		value INTEGER NOT NULL
+	);`,
+	`ALTER TABLE contracts ADD COLUMN shown_document BLOB;
+	ALTER TABLE contracts ADD COLUMN signed_document BLOB;`,
+	`ALTER TABLE contracts ADD COLUMN version INTEGER NOT NULL DEFAULT 0;`,
+	`ALTER TABLE contracts ADD COLUMN event_last_date TEXT NOT NULL DEFAULT '';
+	UPDATE contracts SET event_last_date = CASE WHEN event_date = '' THEN ''
+		ELSE COALESCE(json_extract(data, '$.contract.eventDetails.sessions[#-1].date'), event_date) END;`,
+}
+
+// SQLiteStore is a ContractStore backed by an embedded SQLite database file.
+// The full record is kept as JSON in the data column, the other columns exist so contracts can be looked up, except
+// for the signed documents which are kept as they are in their own columns.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?_busy_timeout=5000&_journal_mode=WAL", path))
+	if err != nil {
+		return nil, fmt.Errorf("failed while opening sqlite database %s with err : %w", path, err)
+	}
+
+	err = migrateSQLite(db)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed while migrating the contracts schema with err : %w", err)
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+func migrateSQLite(db *sql.DB) error {
+	var version int
+	err := db.QueryRow(`PRAGMA user_version`).Scan(&version)
+	if err != nil {
+		return fmt.Errorf("failed while reading the schema version with err : %w", err)
+	}
+
+	for ; version < len(sqliteMigrations); version++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed while starting migration %d with err : %w", version+1, err)
+		}
+
+		_, err = tx.Exec(sqliteMigrations[version])
+		if err == nil {
+			_, err = tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, version+1))
+		}
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed while applying migration %d with err : %w", version+1, err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed while committing migration %d with err : %w", version+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (store *SQLiteStore) Create(record *Record) error {
+	prepareNewRecord(record)
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed while marshalling contract record with err : %w", err)
+	}
+
+	_, err = store.db.Exec(
+		`INSERT INTO contracts (id, created_at, form_id, status, client_email, event_name, event_date, event_last_date, data, shown_document, signed_document) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		record.ID,
+		record.CreatedAt.Format(time.RFC3339Nano),
+		record.FormID,
+		record.Status,
+		record.Contract.ClientDetails.ClientEmail,
+		record.Contract.EventDetails.EventName,
+		sqliteEventDate(record.Contract.EventDetails.EventDate),
+		sqliteEventDate(record.Contract.EventDetails.LastDate()),
+		string(data),
+		record.ShownDocument,
+		record.SignedDocument,
+	)
+	if err != nil {
+		return fmt.Errorf("failed while inserting contract %s with err : %w", record.ID, err)
+	}
+
+	return nil
+}
+
+// Update only writes the record when its version is still the one stored, so of two requests that read the same
+// version only the first one to save goes through.
+func (store *SQLiteStore) Update(record *Record) error {
+	version := record.Version
+	record.Version++
+	err := store.update(record, version)
+	if err != nil {
+		record.Version = version
+	}
+
+	return err
+}
+
+func (store *SQLiteStore) update(record *Record, version int64) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed while marshalling contract record with err : %w", err)
+	}
+
+	result, err := store.db.Exec(
+		`UPDATE contracts SET form_id = ?, status = ?, client_email = ?, event_name = ?, event_date = ?, event_last_date = ?,
+			data = ?, shown_document = COALESCE(?, shown_document), signed_document = COALESCE(?, signed_document),
+			version = ? WHERE id = ? AND version = ?`,
+		record.FormID,
+		record.Status,
+		record.Contract.ClientDetails.ClientEmail,
+		record.Contract.EventDetails.EventName,
+		sqliteEventDate(record.Contract.EventDetails.EventDate),
+		sqliteEventDate(record.Contract.EventDetails.LastDate()),
+		string(data),
+		record.ShownDocument,
+		record.SignedDocument,
+		record.Version,
+		record.ID,
+		version,
+	)
+	if err != nil {
+		return fmt.Errorf("failed while updating contract %s with err : %w", record.ID, err)
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed while checking the update of contract %s with err : %w", record.ID, err)
+	}
+	if updated > 0 {
+		return nil
+	}
+
+	// Nothing was updated, either because the contract does not exist or because it moved on to another version
+	var exists bool
+	err = store.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM contracts WHERE id = ?)`, record.ID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed while checking the update of contract %s with err : %w", record.ID, err)
+	}
+	if !exists {
+		return ErrNotFound
+	}
+
+	return fmt.Errorf("%w : contract %s", ErrConflict, record.ID)
+}
+
+func (store *SQLiteStore) NextNumber(sequence string) (int64, error) {
+	var number int64
+	err := store.db.QueryRow(
+		`INSERT INTO sequences (name, value) VALUES (?, 1) ON CONFLICT (name) DO UPDATE SET value = value + 1 RETURNING value`,
+		sequence,
+	).Scan(&number)
+	if err != nil {
+		return 0, fmt.Errorf("failed while taking the next %s number with err : %w", sequence, err)
+	}
+
+	return number, nil
+}
+
+func (store *SQLiteStore) RedeemPromoCode(code string, maxUses int64) (int64, error) {
+	if maxUses == 0 {
+		return store.NextNumber(promoCodeSequence(code))
+	}
+
+	// The update is skipped once the limit is reached, which leaves no row to return
+	var uses int64
+	err := store.db.QueryRow(
+		`INSERT INTO sequences (name, value) VALUES (?, 1) ON CONFLICT (name) DO UPDATE SET value = value + 1 WHERE value < ? RETURNING value`,
+		promoCodeSequence(code), maxUses,
+	).Scan(&uses)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%w : %s", ErrPromoCodeUsedUp, code)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed while redeeming promo code %s with err : %w", code, err)
+	}
+
+	return uses, nil
+}
+
+func (store *SQLiteStore) ReleasePromoCode(code string) error {
+	_, err := store.db.Exec(`UPDATE sequences SET value = value - 1 WHERE name = ? AND value > 0`, promoCodeSequence(code))
+	if err != nil {
+		return fmt.Errorf("failed while giving back a use of promo code %s with err : %w", code, err)
+	}
+
+	return nil
+}
+
+func (store *SQLiteStore) Get(id string) (*Record, error) {
+	var data string
+	var shown, signed []byte
+	var version int64
+	err := store.db.QueryRow(`SELECT data, shown_document, signed_document, version FROM contracts WHERE id = ?`, id).Scan(&data, &shown, &signed, &version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed while reading contract %s with err : %w", id, err)
+	}
+
+	var record Record
+	if err := json.Unmarshal([]byte(data), &record); err != nil {
+		return nil, fmt.Errorf("failed while unmarshalling contract %s with err : %w", id, err)
+	}
+	record.ShownDocument, record.SignedDocument = shown, signed
+	record.Version = version
+
+	return &record, nil
+}
+
+func (store *SQLiteStore) List(filter Filter) ([]*Record, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.ClientEmail != "" {
+		conditions = append(conditions, "client_email = ? COLLATE NOCASE")
+		args = append(args, filter.ClientEmail)
+	}
+	if filter.EventName != "" {
+		conditions = append(conditions, `event_name LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(filter.EventName)+"%")
+	}
+	// Events are matched from their first to their last day, event_last_date falls back to the first for events
+	// whose last day could not be read
+	if !filter.EventDateFrom.IsZero() {
+		conditions = append(conditions, "event_date != '' AND MAX(event_date, event_last_date) >= ?")
+		args = append(args, filter.EventDateFrom.Format(EventDateLayout))
+	}
+	if !filter.EventDateTo.IsZero() {
+		conditions = append(conditions, "event_date != '' AND event_date <= ?")
+		args = append(args, filter.EventDateTo.Format(EventDateLayout))
+	}
+
+	if filter.NeedsReview {
+		conditions = append(conditions, "json_extract(data, '$.signature.needsReview') = 1")
+	}
+
+	query := "SELECT data, version FROM contracts"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at DESC"
+	if filter.Limit > 0 || filter.Offset > 0 {
+		limit := filter.Limit
+		if limit <= 0 {
+			limit = -1
+		}
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, limit, filter.Offset)
+	}
+
+	rows, err := store.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed while listing contracts with err : %w", err)
+	}
+	defer rows.Close()
+
+	records := []*Record{}
+	for rows.Next() {
+		var data string
+		var version int64
+		if err := rows.Scan(&data, &version); err != nil {
+			return nil, fmt.Errorf("failed while scanning contract row with err : %w", err)
+		}
+
+		var record Record
+		if err := json.Unmarshal([]byte(data), &record); err != nil {
+			return nil, fmt.Errorf("failed while unmarshalling contract row with err : %w", err)
+		}
+		record.Version = version
+		records = append(records, &record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed while iterating contract rows with err : %w", err)
+	}
+
+	return records, nil
+}
+
+func (store *SQLiteStore) Close() error {
+	return store.db.Close()
+}
+
+// sqliteEventDate normalizes a date of the event so it can be compared as text, unparseable dates are stored empty.
+func sqliteEventDate(date Date) string {
+	if !date.Valid() {
+		return ""
+	}
+	return date.Time().Format(EventDateLayout)
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
@@ -0,0 +1,167 @@
+package contract
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status is where a contract is in its lifecycle, following the phases described in the terms.
+type Status string
+
+const (
+	// StatusDraft contracts have been saved but not shared with the client yet.
+	StatusDraft Status = "draft"
+	// StatusSent contracts are waiting for the client's signature.
+	StatusSent Status = "sent"
+	// StatusSigned contracts have been signed and the booking fee is due.
+	StatusSigned Status = "signed"
+	// StatusPaid contracts have received the project payment in full, editing can begin.
+	StatusPaid Status = "paid"
+	// StatusDelivered contracts have had their first digital copy delivered and are open for revisions.
+	StatusDelivered Status = "delivered"
+	// StatusCompleted contracts have had their final soft copy delivered.
+	StatusCompleted Status = "completed"
+	// StatusCancelled contracts were terminated by the client under the cancellation clause.
+	StatusCancelled Status = "cancelled"
+)
+
+var (
+	ErrInvalidStatus        = errors.New("invalid contract status")
+	ErrInvalidTransition    = errors.New("invalid contract status transition")
+	ErrRescheduleNotAllowed = errors.New("contract cannot be rescheduled")
+	ErrInvalidReschedule    = errors.New("invalid reschedule")
+)
+
+// transitions lists the statuses a contract may move to from each status.
+var transitions = map[Status][]Status{
+	StatusDraft:     {StatusSent, StatusCancelled},
+	StatusSent:      {StatusSigned, StatusCancelled},
+	StatusSigned:    {StatusPaid, StatusCancelled},
+	StatusPaid:      {StatusDelivered, StatusCancelled},
+	StatusDelivered: {StatusCompleted},
+	StatusCompleted: {},
+	StatusCancelled: {},
+}
+
+// rescheduleNotice is how long before the event a reschedule has to be requested.
+const rescheduleNotice = 24 * time.Hour
+
+// StatusChange records when a contract moved to a status.
+type StatusChange struct {
+	Status Status    `json:"status"`
+	At     time.Time `json:"at"`
+	Note   string    `json:"note,omitempty"`
+}
+
+// Reschedule records an event date change made under the reschedule policy.
+type Reschedule struct {
+	FromDate         Date     `json:"fromDate"`
+	ToDate           Date     `json:"toDate"`
+	FromCoverageTime Coverage `json:"fromCoverageTime"`
+	ToCoverageTime   Coverage `json:"toCoverageTime"`
+	// FromDeliveryDates and ToDeliveryDates are the delivery dates of the deliverables, in order, when the reschedule
+	// moved them.
+	FromDeliveryDates []Date    `json:"fromDeliveryDates,omitempty"`
+	ToDeliveryDates   []Date    `json:"toDeliveryDates,omitempty"`
+	At                time.Time `json:"at"`
+	Note              string    `json:"note,omitempty"`
+}
+
+func ParseStatus(value string) (Status, error) {
+	status := Status(value)
+	if _, ok := transitions[status]; !ok {
+		return "", fmt.Errorf("%w : %s", ErrInvalidStatus, value)
+	}
+	return status, nil
+}
+
+// CanTransition reports whether a contract in this status may move to the next one.
+func (status Status) CanTransition(next Status) bool {
+	for _, allowed := range transitions[status] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// NewRecord returns a draft record for the contract.
+func NewRecord(details Contract) *Record {
+	now := time.Now().UTC()
+	return &Record{
+		ID:            uuid.NewString(),
+		CreatedAt:     now,
+		Status:        StatusDraft,
+		StatusHistory: []StatusChange{{Status: StatusDraft, At: now}},
+		Contract:      details,
+	}
+}
+
+// Transition moves the record to the next status, rejecting moves the lifecycle does not allow.
+func (record *Record) Transition(next Status, note string) error {
+	if !record.Status.CanTransition(next) {
+		return fmt.Errorf("%w : cannot move a %s contract to %s", ErrInvalidTransition, record.Status, next)
+	}
+
+	record.Status = next
+	record.StatusHistory = append(record.StatusHistory, StatusChange{Status: next, At: time.Now().UTC(), Note: note})
+
+	return nil
+}
+
+// Reschedule moves the event to a new date and coverage time, and the deliverables to new delivery dates when given
+// one for each of them in order. The reschedule policy allows this once, when requested at least 24 hours before the
+// event and only after the project payment is made in full.
+func (record *Record) Reschedule(eventDate Date, eventCoverageTime Coverage, deliveryDates []Date, note string) error {
+	if record.Status != StatusPaid {
+		return fmt.Errorf("%w : project payment has to be made in full before rescheduling, contract is %s", ErrRescheduleNotAllowed, record.Status)
+	}
+
+	if len(record.Contract.EventDetails.Sessions) > 0 {
+		return fmt.Errorf("%w : events with several sessions have to be reissued to change their dates", ErrRescheduleNotAllowed)
+	}
+
+	if len(record.Reschedules) > 0 {
+		return fmt.Errorf("%w : contract has already been rescheduled once", ErrRescheduleNotAllowed)
+	}
+
+	now := time.Now().UTC()
+	currentStart, ok := record.Contract.EventDetails.Starts()
+	if !ok {
+		currentStart, ok = record.Contract.EventDetails.ParsedEventDate()
+	}
+	if ok && now.Add(rescheduleNotice).After(currentStart) {
+		return fmt.Errorf("%w : reschedules have to be requested at least 24 hours before the event", ErrRescheduleNotAllowed)
+	}
+
+	deliverables := record.Contract.DeliverableDetails
+	if len(deliveryDates) > 0 && len(deliveryDates) != len(deliverables) {
+		return fmt.Errorf("%w : a delivery date is needed for each of the %d deliverables", ErrInvalidReschedule, len(deliverables))
+	}
+
+	if eventCoverageTime.IsZero() {
+		eventCoverageTime = record.Contract.EventDetails.EventCoverageTime
+	}
+
+	reschedule := Reschedule{
+		FromDate:         record.Contract.EventDetails.EventDate,
+		ToDate:           eventDate,
+		FromCoverageTime: record.Contract.EventDetails.EventCoverageTime,
+		ToCoverageTime:   eventCoverageTime,
+		At:               now,
+		Note:             note,
+	}
+	for i, deliveryDate := range deliveryDates {
+		reschedule.FromDeliveryDates = append(reschedule.FromDeliveryDates, deliverables[i].DeliveryDate)
+		reschedule.ToDeliveryDates = append(reschedule.ToDeliveryDates, deliveryDate)
+		deliverables[i].DeliveryDate = deliveryDate
+	}
+	record.Reschedules = append(record.Reschedules, reschedule)
+	record.Contract.EventDetails.EventDate = eventDate
+	record.Contract.EventDetails.EventCoverageTime = eventCoverageTime
+
+	return nil
+}
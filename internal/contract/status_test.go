@@ -0,0 +1,95 @@
+package contract
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTransition(t *testing.T) {
+	tests := []struct {
+		from, to Status
+		allowed  bool
+	}{
+		{StatusDraft, StatusSent, true},
+		{StatusDraft, StatusSigned, false},
+		{StatusSent, StatusSigned, true},
+		{StatusSent, StatusPaid, false},
+		{StatusSigned, StatusPaid, true},
+		{StatusSigned, StatusCancelled, true},
+		{StatusPaid, StatusDelivered, true},
+		{StatusDelivered, StatusCompleted, true},
+		{StatusDelivered, StatusCancelled, false},
+		{StatusCompleted, StatusDraft, false},
+		{StatusCancelled, StatusSent, false},
+	}
+
+	for _, test := range tests {
+		record := &Record{Status: test.from}
+		err := record.Transition(test.to, "note")
+		if test.allowed {
+			if err != nil {
+				t.Errorf("%s -> %s : %v", test.from, test.to, err)
+				continue
+			}
+			if record.Status != test.to || len(record.StatusHistory) != 1 || record.StatusHistory[0].Note != "note" {
+				t.Errorf("%s -> %s left status %s and history %+v", test.from, test.to, record.Status, record.StatusHistory)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("%s -> %s returned %v, want ErrInvalidTransition", test.from, test.to, err)
+		}
+		if record.Status != test.from {
+			t.Errorf("%s -> %s moved the record to %s", test.from, test.to, record.Status)
+		}
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	if status, err := ParseStatus("signed"); err != nil || status != StatusSigned {
+		t.Errorf("ParseStatus(signed) = %s, %v", status, err)
+	}
+	if _, err := ParseStatus("archived"); !errors.Is(err, ErrInvalidStatus) {
+		t.Errorf("ParseStatus(archived) returned %v, want ErrInvalidStatus", err)
+	}
+}
+
+func TestReschedule(t *testing.T) {
+	eventDate := time.Now().AddDate(0, 1, 0)
+	newDate := NewDate(eventDate.AddDate(0, 0, 7))
+
+	record := NewRecord(testContract("Priya Raman", eventDate))
+	if err := record.Reschedule(newDate, Coverage{}, nil, ""); !errors.Is(err, ErrRescheduleNotAllowed) {
+		t.Fatalf("reschedule of an unpaid contract returned %v", err)
+	}
+
+	record.Status = StatusPaid
+	record.Contract.DeliverableDetails = []Deliverable{{Description: "Edited photos", DeliveryDate: newDate}}
+	if err := record.Reschedule(newDate, Coverage{}, []Date{newDate, newDate}, ""); !errors.Is(err, ErrInvalidReschedule) {
+		t.Fatalf("reschedule with a delivery date too many returned %v, want ErrInvalidReschedule", err)
+	}
+	deliveryDate := newDate.AddDays(30)
+	if err := record.Reschedule(newDate, Coverage{}, []Date{deliveryDate}, "family emergency"); err != nil {
+		t.Fatalf("Reschedule : %v", err)
+	}
+	if record.Contract.EventDetails.EventDate != newDate || len(record.Reschedules) != 1 {
+		t.Errorf("event date = %s with %d reschedules", record.Contract.EventDetails.EventDate, len(record.Reschedules))
+	}
+	if record.Contract.DeliverableDetails[0].DeliveryDate != deliveryDate || record.Reschedules[0].FromDeliveryDates[0] != newDate {
+		t.Errorf("deliverables = %+v after %+v", record.Contract.DeliverableDetails, record.Reschedules[0])
+	}
+	if record.Contract.EventDetails.EventCoverageTime.IsZero() {
+		t.Error("the coverage time was dropped when no new one was given")
+	}
+
+	if err := record.Reschedule(NewDate(eventDate), Coverage{}, nil, ""); !errors.Is(err, ErrRescheduleNotAllowed) {
+		t.Errorf("second reschedule returned %v, want ErrRescheduleNotAllowed", err)
+	}
+
+	soon := NewRecord(testContract("Priya Raman", time.Now()))
+	soon.Status = StatusPaid
+	if err := soon.Reschedule(newDate, Coverage{}, nil, ""); !errors.Is(err, ErrRescheduleNotAllowed) {
+		t.Errorf("reschedule on the day of the event returned %v, want ErrRescheduleNotAllowed", err)
+	}
+}
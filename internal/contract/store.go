@@ -0,0 +1,165 @@
+package contract
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/branding"
+)
+
+// ErrNotFound is returned by a ContractStore when no contract exists for the given ID.
+var ErrNotFound = errors.New("contract not found")
+
+// ErrConflict is returned by ContractStore.Update when the record was changed since it was read.
+var ErrConflict = errors.New("contract was changed by another request")
+
+// ErrPromoCodeUsedUp is returned by a ContractStore when a promo code has been redeemed as often as it may be.
+var ErrPromoCodeUsedUp = errors.New("promo code has been used up")
+
+// Record is an issued contract along with the metadata needed to find it again.
+type Record struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Version counts the updates of the record, an update only goes through when it is made to the latest version.
+	Version                 int64          `json:"version"`
+	FormID                  string         `json:"formId"`
+	FormURL                 string         `json:"formUrl"`
+	FormEditURL             string         `json:"formEditUrl,omitempty"`
+	FormImageFileIDs        []string       `json:"formImageFileIds,omitempty"`
+	FormSignatureQuestionID string         `json:"formSignatureQuestionId,omitempty"`
+	SigningToken            string         `json:"signingToken,omitempty"`
+	Status                  Status         `json:"status"`
+	StatusHistory           []StatusChange `json:"statusHistory"`
+	Reschedules             []Reschedule   `json:"reschedules,omitempty"`
+	Signature               *Signature     `json:"signature,omitempty"`
+	Ledger                  []LedgerEntry  `json:"ledger,omitempty"`
+	Overtime                []Overtime     `json:"overtime,omitempty"`
+	InvoiceNumber           string         `json:"invoiceNumber,omitempty"`
+	// Invoice is the invoice numbered InvoiceNumber as it was last sent, with the payments received until then.
+	Invoice *Invoice `json:"invoice,omitempty"`
+	// TermsVersion is the version of the terms the contract was issued under, contracts issued before terms were
+	// versioned leave it empty.
+	TermsVersion string `json:"termsVersion,omitempty"`
+	// Studio is who the contract was issued by, its documents are printed with it. Contracts issued before it was
+	// kept leave it empty and are printed with the current branding.
+	Studio   *branding.Studio `json:"studio,omitempty"`
+	Contract Contract         `json:"contract"`
+	// ShownDocument is the contract PDF the client is shown, saved when the form or the signing link is created. The
+	// pages of the form are images of it and the signing page serves it, the signature's DocumentHash is its SHA-256.
+	// SignedDocument is the same contract with the client's signature and the audit certificate. Both are kept as
+	// they were issued, apart from the JSON, so later changes to the contract, the terms or the branding do not alter
+	// what the client is shown or what was signed.
+	ShownDocument  []byte `json:"-"`
+	SignedDocument []byte `json:"-"`
+}
+
+// Filter narrows down the contracts returned by ContractStore.List. Zero values match everything.
+type Filter struct {
+	Status      Status
+	ClientEmail string
+	// EventName matches contracts whose event name contains it, ignoring case.
+	EventName string
+	// EventDateFrom and EventDateTo bound the days of the event, both inclusive. Split events match when any day from
+	// their first session to their last one is within them.
+	EventDateFrom time.Time
+	EventDateTo   time.Time
+	// NeedsReview only matches contracts whose signature did not match the client name.
+	NeedsReview bool
+	Limit       int
+	Offset      int
+}
+
+func (filter *Filter) matches(record *Record) bool {
+	if filter.Status != "" && filter.Status != record.Status {
+		return false
+	}
+
+	if filter.ClientEmail != "" && !strings.EqualFold(filter.ClientEmail, record.Contract.ClientDetails.ClientEmail) {
+		return false
+	}
+
+	if filter.EventName != "" && !strings.Contains(strings.ToLower(record.Contract.EventDetails.EventName), strings.ToLower(filter.EventName)) {
+		return false
+	}
+
+	if !filter.EventDateFrom.IsZero() || !filter.EventDateTo.IsZero() {
+		firstDate, ok := record.Contract.EventDetails.ParsedEventDate()
+		if !ok {
+			return false
+		}
+		lastDate := firstDate
+		if last := record.Contract.EventDetails.LastDate(); last.Valid() && last.Time().After(firstDate) {
+			lastDate = last.Time()
+		}
+		if !filter.EventDateFrom.IsZero() && lastDate.Before(filter.EventDateFrom) {
+			return false
+		}
+		if !filter.EventDateTo.IsZero() && firstDate.After(filter.EventDateTo) {
+			return false
+		}
+	}
+
+	if filter.NeedsReview && (record.Signature == nil || !record.Signature.NeedsReview) {
+		return false
+	}
+
+	return true
+}
+
+// ContractStore persists every contract that has been sent out to a client.
+type ContractStore interface {
+	// Create assigns an ID and creation time to the record and saves it.
+	Create(record *Record) error
+	// Get returns the record with the given ID or ErrNotFound.
+	Get(id string) (*Record, error)
+	// Update overwrites a previously created record and moves it to the next version, or returns ErrNotFound. It
+	// returns ErrConflict when the record was updated since it was read. Documents left out of the record are kept,
+	// signed documents are never removed.
+	Update(record *Record) error
+	// List returns the records matching the filter, newest first, without their signed documents.
+	List(filter Filter) ([]*Record, error)
+	// NextNumber returns the next number of the named sequence, starting at one.
+	NextNumber(sequence string) (int64, error)
+	// RedeemPromoCode counts a use of the promo code and returns how often it has been used, or ErrPromoCodeUsedUp
+	// when it has already been used maxUses times. Codes without a limit have a maxUses of zero.
+	RedeemPromoCode(code string, maxUses int64) (int64, error)
+	// ReleasePromoCode gives back a use of the promo code, for contracts that failed to be issued after redeeming it.
+	ReleasePromoCode(code string) error
+	Close() error
+}
+
+// promoCodeSequence is the sequence the uses of a promo code are counted in.
+func promoCodeSequence(code string) string {
+	return "promo-code:" + code
+}
+
+func prepareNewRecord(record *Record) {
+	if record.ID == "" {
+		record.ID = uuid.NewString()
+	}
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = time.Now().UTC()
+	}
+	if record.Status == "" {
+		record.Status = StatusDraft
+		record.StatusHistory = []StatusChange{{Status: StatusDraft, At: record.CreatedAt}}
+	}
+}
+
+func cloneRecord(record *Record) (*Record, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed while marshalling contract record with err : %w", err)
+	}
+
+	var clone Record
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil, fmt.Errorf("failed while unmarshalling contract record with err : %w", err)
+	}
+
+	return &clone, nil
+}
@@ -0,0 +1,259 @@
+package contract
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/money"
+)
+
+// testStores opens each ContractStore, the SQLite one in a database of its own.
+func testStores(t *testing.T) map[string]ContractStore {
+	t.Helper()
+
+	sqlite, err := NewSQLiteStore(filepath.Join(t.TempDir(), "contracts.db"))
+	if err != nil {
+		t.Fatalf("failed to open sqlite store : %v", err)
+	}
+	t.Cleanup(func() { sqlite.Close() })
+
+	return map[string]ContractStore{
+		"memory": NewMemoryStore(),
+		"sqlite": sqlite,
+	}
+}
+
+func testContract(clientName string, eventDate time.Time) Contract {
+	coverage, _ := ParseCoverage("4pm - 8pm")
+	return Contract{
+		ClientDetails: ClientDetails{ClientName: clientName, ClientEmail: "client@example.com"},
+		EventDetails: EventDetails{
+			EventName:         "House Warming",
+			EventDate:         NewDate(eventDate),
+			EventCoverageTime: coverage,
+			EventVenue:        "Boston, MA",
+		},
+		PaymentDetails: PaymentDetails{
+			TotalAmount:  money.FromMajor(1500, money.DefaultCurrency),
+			PerHourExtra: money.FromMajor(150, money.DefaultCurrency),
+		},
+	}
+}
+
+func TestStoreCreateGetUpdate(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			record := NewRecord(testContract("Priya Raman", time.Date(2030, 5, 20, 0, 0, 0, 0, time.UTC)))
+			if err := store.Create(record); err != nil {
+				t.Fatalf("Create : %v", err)
+			}
+
+			stored, err := store.Get(record.ID)
+			if err != nil {
+				t.Fatalf("Get : %v", err)
+			}
+			if stored.Contract.ClientDetails.ClientName != "Priya Raman" || stored.Status != StatusDraft {
+				t.Fatalf("stored record = %+v", stored)
+			}
+			if got := stored.Contract.EventDetails.EventCoverageTime.Format(stored.Contract.EventDetails.EventDate); got != "4:00 PM - 8:00 PM EDT" {
+				t.Errorf("coverage = %q", got)
+			}
+
+			if err := stored.Transition(StatusSent, "sent"); err != nil {
+				t.Fatalf("Transition : %v", err)
+			}
+			if err := store.Update(stored); err != nil {
+				t.Fatalf("Update : %v", err)
+			}
+			updated, err := store.Get(record.ID)
+			if err != nil {
+				t.Fatalf("Get : %v", err)
+			}
+			if updated.Status != StatusSent {
+				t.Errorf("updated record has status %s", updated.Status)
+			}
+
+			if _, err := store.Get("missing"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get of a missing contract returned %v", err)
+			}
+			missing := NewRecord(testContract("Nobody", time.Now()))
+			if err := store.Update(missing); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Update of a missing contract returned %v", err)
+			}
+		})
+	}
+}
+
+func TestStoreList(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			for i, day := range []int{10, 20, 30} {
+				record := NewRecord(testContract("Client", time.Date(2030, 5, day, 0, 0, 0, 0, time.UTC)))
+				record.CreatedAt = time.Date(2026, 1, i+1, 0, 0, 0, 0, time.UTC)
+				if day == 20 {
+					record.Contract.EventDetails.EventName = "Garden Wedding"
+					record.Status = StatusSigned
+				}
+				// The event on the 10th is split, with its reception a week later
+				if day == 10 {
+					details := &record.Contract.EventDetails
+					details.Sessions = []Session{
+						{Name: "Mehendi", Date: details.EventDate, CoverageTime: details.EventCoverageTime, Venue: "Home"},
+						{Name: "Reception", Date: details.EventDate.AddDays(7), CoverageTime: details.EventCoverageTime, Venue: "Boston, MA"},
+					}
+				}
+				if err := store.Create(record); err != nil {
+					t.Fatalf("Create : %v", err)
+				}
+			}
+
+			tests := []struct {
+				name   string
+				filter Filter
+				want   []int
+			}{
+				{"everything newest first", Filter{}, []int{30, 20, 10}},
+				{"status", Filter{Status: StatusSigned}, []int{20}},
+				{"event name", Filter{EventName: "wedding"}, []int{20}},
+				{"event dates", Filter{EventDateFrom: time.Date(2030, 5, 18, 0, 0, 0, 0, time.UTC), EventDateTo: time.Date(2030, 5, 30, 0, 0, 0, 0, time.UTC)}, []int{30, 20}},
+				{"later session", Filter{EventDateFrom: time.Date(2030, 5, 16, 0, 0, 0, 0, time.UTC), EventDateTo: time.Date(2030, 5, 17, 0, 0, 0, 0, time.UTC)}, []int{10}},
+				{"until the event", Filter{EventDateTo: time.Date(2030, 5, 10, 0, 0, 0, 0, time.UTC)}, []int{10}},
+				{"page", Filter{Limit: 1, Offset: 1}, []int{20}},
+			}
+			for _, test := range tests {
+				records, err := store.List(test.filter)
+				if err != nil {
+					t.Fatalf("%s : List : %v", test.name, err)
+				}
+				var days []int
+				for _, record := range records {
+					days = append(days, record.Contract.EventDetails.EventDate.Time().Day())
+				}
+				if len(days) != len(test.want) {
+					t.Errorf("%s : listed event days %v, want %v", test.name, days, test.want)
+					continue
+				}
+				for i := range days {
+					if days[i] != test.want[i] {
+						t.Errorf("%s : listed event days %v, want %v", test.name, days, test.want)
+						break
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestStoreKeepsDocuments(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			record := NewRecord(testContract("Priya Raman", time.Now()))
+			record.ShownDocument = []byte("shown")
+			if err := store.Create(record); err != nil {
+				t.Fatalf("Create : %v", err)
+			}
+
+			stored, _ := store.Get(record.ID)
+			stored.ShownDocument = nil
+			stored.SignedDocument = []byte("signed")
+			if err := store.Update(stored); err != nil {
+				t.Fatalf("Update : %v", err)
+			}
+
+			stored, _ = store.Get(record.ID)
+			if !bytes.Equal(stored.ShownDocument, []byte("shown")) || !bytes.Equal(stored.SignedDocument, []byte("signed")) {
+				t.Errorf("documents = %q and %q", stored.ShownDocument, stored.SignedDocument)
+			}
+
+			listed, err := store.List(Filter{})
+			if err != nil {
+				t.Fatalf("List : %v", err)
+			}
+			if len(listed) != 1 || listed[0].ShownDocument != nil || listed[0].SignedDocument != nil {
+				t.Errorf("List returned %d records with their documents", len(listed))
+			}
+		})
+	}
+}
+
+func TestStoreUpdateConflict(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			record := NewRecord(testContract("Priya Raman", time.Now()))
+			if err := store.Create(record); err != nil {
+				t.Fatalf("Create : %v", err)
+			}
+
+			first, _ := store.Get(record.ID)
+			second, _ := store.Get(record.ID)
+
+			first.InvoiceNumber = "INV-000001"
+			if err := store.Update(first); err != nil {
+				t.Fatalf("Update : %v", err)
+			}
+
+			second.InvoiceNumber = "INV-000002"
+			if err := store.Update(second); !errors.Is(err, ErrConflict) {
+				t.Fatalf("Update of a stale record returned %v, want ErrConflict", err)
+			}
+			if second.Version != 0 {
+				t.Errorf("version of the rejected record moved to %d", second.Version)
+			}
+
+			stored, _ := store.Get(record.ID)
+			if stored.InvoiceNumber != "INV-000001" {
+				t.Errorf("invoice number = %q, the stale update went through", stored.InvoiceNumber)
+			}
+		})
+	}
+}
+
+func TestStoreNextNumber(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			// Each kind of document is numbered on its own
+			for _, want := range []struct {
+				kind   string
+				number int64
+			}{{"invoice", 1}, {"invoice", 2}, {"receipt", 1}, {"invoice", 3}} {
+				if number, err := store.NextNumber(want.kind); err != nil || number != want.number {
+					t.Errorf("NextNumber(%s) = %d, %v, want %d", want.kind, number, err, want.number)
+				}
+			}
+		})
+	}
+}
+
+func TestStorePromoCodes(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			for want := int64(1); want <= 2; want++ {
+				uses, err := store.RedeemPromoCode("SPRING", 2)
+				if err != nil || uses != want {
+					t.Fatalf("RedeemPromoCode = %d, %v, want %d", uses, err, want)
+				}
+			}
+			if _, err := store.RedeemPromoCode("SPRING", 2); !errors.Is(err, ErrPromoCodeUsedUp) {
+				t.Fatalf("third use returned %v, want ErrPromoCodeUsedUp", err)
+			}
+
+			if err := store.ReleasePromoCode("SPRING"); err != nil {
+				t.Fatalf("ReleasePromoCode : %v", err)
+			}
+			if uses, err := store.RedeemPromoCode("SPRING", 2); err != nil || uses != 2 {
+				t.Errorf("use after a release = %d, %v, want 2", uses, err)
+			}
+
+			// Codes without a limit are only counted, and releasing an unused code does not go below zero
+			if err := store.ReleasePromoCode("REFERRAL"); err != nil {
+				t.Fatalf("ReleasePromoCode : %v", err)
+			}
+			if uses, err := store.RedeemPromoCode("REFERRAL", 0); err != nil || uses != 1 {
+				t.Errorf("first use of an unlimited code = %d, %v, want 1", uses, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package gformscreator
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/contract"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/forms/v1"
+)
+
+// FakeFile is an image uploaded to a FakeFileStore.
+type FakeFile struct {
+	ID      string
+	Name    string
+	Content []byte
+	Public  bool
+}
+
+// FakeFileStore is an in-process FileStore that records what would have been stored in Drive.
+type FakeFileStore struct {
+	mu sync.Mutex
+	// Err is returned by every call when set.
+	Err         error
+	Files       map[string]*FakeFile
+	SharedForms []string
+	Deleted     []string
+	nextID      int
+}
+
+func NewFakeFileStore() *FakeFileStore {
+	return &FakeFileStore{Files: map[string]*FakeFile{}}
+}
+
+func (store *FakeFileStore) UploadImage(name string, content io.Reader) (*drive.File, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if store.Err != nil {
+		return nil, store.Err
+	}
+
+	data, err := ioutil.ReadAll(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed while reading image content with err : %w", err)
+	}
+
+	store.nextID++
+	file := &FakeFile{
+		ID:      fmt.Sprintf("fake-file-%d", store.nextID),
+		Name:    name,
+		Content: data,
+		Public:  true,
+	}
+	store.Files[file.ID] = file
+
+	return &drive.File{
+		Id:             file.ID,
+		Name:           file.Name,
+		WebContentLink: fmt.Sprintf("https://drive.fake/%s", file.ID),
+	}, nil
+}
+
+func (store *FakeFileStore) MoveFormToSharedDirectory(formID string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if store.Err != nil {
+		return store.Err
+	}
+
+	store.SharedForms = append(store.SharedForms, formID)
+	return nil
+}
+
+func (store *FakeFileStore) RevokePublicAccess(fileID string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if store.Err != nil {
+		return store.Err
+	}
+
+	file, ok := store.Files[fileID]
+	if !ok {
+		return fmt.Errorf("failed while removing the public permission of the file with ID %s with err : file not found", fileID)
+	}
+	file.Public = false
+	return nil
+}
+
+func (store *FakeFileStore) DeleteFile(fileID string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if store.Err != nil {
+		return store.Err
+	}
+
+	delete(store.Files, fileID)
+	store.Deleted = append(store.Deleted, fileID)
+	return nil
+}
+
+// FakeForm is a form created by a FakeFormBuilder along with its items and responses.
+type FakeForm struct {
+	Form      *forms.Form
+	Contract  contract.Contract
+	Items     []*forms.Item
+	Responses []SignatureResponse
+}
+
+// FakeFormBuilder is an in-process FormBuilder that records the forms and items it was asked to create.
+type FakeFormBuilder struct {
+	mu sync.Mutex
+	// Err is returned by every call when set.
+	Err    error
+	Forms  map[string]*FakeForm
+	nextID int
+}
+
+func NewFakeFormBuilder() *FakeFormBuilder {
+	return &FakeFormBuilder{Forms: map[string]*FakeForm{}}
+}
+
+func (builder *FakeFormBuilder) CreateFormWithTitleAndDescription(contract *contract.Contract, title *string, description *string) (*forms.Form, error) {
+	builder.mu.Lock()
+	defer builder.mu.Unlock()
+
+	if builder.Err != nil {
+		return nil, builder.Err
+	}
+
+	builder.nextID++
+	formID := fmt.Sprintf("fake-form-%d", builder.nextID)
+	form := &forms.Form{
+		FormId: formID,
+		Info: &forms.Info{
+			Title:       *title,
+			Description: *description,
+		},
+		ResponderUri: fmt.Sprintf("https://forms.fake/%s/viewform", formID),
+	}
+	builder.Forms[formID] = &FakeForm{Form: form, Contract: *contract}
+
+	return form, nil
+}
+
+func (builder *FakeFormBuilder) CreateImageItem(form *forms.Form, title string, sourceURI *string, index int64) error {
+	return builder.insertItem(form.FormId, index, &forms.Item{
+		Title:     title,
+		ImageItem: &forms.ImageItem{Image: &forms.Image{SourceUri: *sourceURI}},
+	})
+}
+
+func (builder *FakeFormBuilder) CreateSignatureItem(form *forms.Form, title string, index int64) (*string, error) {
+	questionID := fmt.Sprintf("%s-question-%d", form.FormId, index)
+	err := builder.insertItem(form.FormId, index, &forms.Item{
+		Title: title,
+		QuestionItem: &forms.QuestionItem{
+			Question: &forms.Question{QuestionId: questionID, Required: true, TextQuestion: &forms.TextQuestion{}},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &questionID, nil
+}
+
+func (builder *FakeFormBuilder) FetchSignatureResponse(formID string, questionID string) (*SignatureResponse, error) {
+	builder.mu.Lock()
+	defer builder.mu.Unlock()
+
+	if builder.Err != nil {
+		return nil, builder.Err
+	}
+
+	fakeForm, ok := builder.Forms[formID]
+	if !ok {
+		return nil, fmt.Errorf("failure to list the responses of the form %s with error : form not found", formID)
+	}
+	if len(fakeForm.Responses) == 0 {
+		return nil, nil
+	}
+
+	latest := fakeForm.Responses[len(fakeForm.Responses)-1]
+	return &latest, nil
+}
+
+// Respond simulates the client submitting the form with their printed name.
+func (builder *FakeFormBuilder) Respond(formID string, name string) error {
+	builder.mu.Lock()
+	defer builder.mu.Unlock()
+
+	fakeForm, ok := builder.Forms[formID]
+	if !ok {
+		return fmt.Errorf("form %s not found", formID)
+	}
+
+	fakeForm.Responses = append(fakeForm.Responses, SignatureResponse{Name: name, SubmittedAt: time.Now().UTC()})
+	return nil
+}
+
+func (builder *FakeFormBuilder) insertItem(formID string, index int64, item *forms.Item) error {
+	builder.mu.Lock()
+	defer builder.mu.Unlock()
+
+	if builder.Err != nil {
+		return builder.Err
+	}
+
+	fakeForm, ok := builder.Forms[formID]
+	if !ok {
+		return fmt.Errorf("failure to create an item in the form %s with error : form not found", formID)
+	}
+	if index < 0 || index > int64(len(fakeForm.Items)) {
+		return fmt.Errorf("failure to create an item in the form %s with error : index %d out of range", formID, index)
+	}
+
+	fakeForm.Items = append(fakeForm.Items, nil)
+	copy(fakeForm.Items[index+1:], fakeForm.Items[index:])
+	fakeForm.Items[index] = item
+
+	return nil
+}
@@ -4,18 +4,39 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"io/ioutil"
-	"path/filepath"
+	"io"
 	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/branding"
 	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/contract"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/pricing"
 	"google.golang.org/api/drive/v3"
 	"google.golang.org/api/forms/v1"
 	"google.golang.org/api/option"
 )
 
+// FileStore keeps the files behind a contract form, implemented by DriveService.
+type FileStore interface {
+	// UploadImage uploads a publicly readable jpeg and returns the file with its web content link.
+	UploadImage(name string, content io.Reader) (*drive.File, error)
+	// MoveFormToSharedDirectory moves a form into the folder shared with staff.
+	MoveFormToSharedDirectory(formID string) error
+	// RevokePublicAccess removes the link sharing an uploaded image was given so a form could copy it.
+	RevokePublicAccess(fileID string) error
+	// DeleteFile deletes an uploaded image or a form.
+	DeleteFile(fileID string) error
+}
+
+// FormBuilder creates contract forms and reads their responses, implemented by FormsService.
+type FormBuilder interface {
+	CreateFormWithTitleAndDescription(contract *contract.Contract, title *string, description *string) (*forms.Form, error)
+	CreateImageItem(form *forms.Form, title string, sourceURI *string, index int64) error
+	CreateSignatureItem(form *forms.Form, title string, index int64) (*string, error)
+	FetchSignatureResponse(formID string, questionID string) (*SignatureResponse, error)
+}
+
 type DriveService struct {
 	*drive.Service
 }
@@ -50,23 +71,36 @@ func NewDriveService() (*DriveService, error) {
 	return &DriveService{driveService}, nil
 }
 
-func (driveService *DriveService) uploadImageToDrive(imgPath string) (*string, error) {
+// imagesFolderID is the Drive folder the contract images are uploaded to before being added to a form.
+const imagesFolderID = "1UX-0xXQPRbV5aj1G_NNX06gyODgakvQP"
+
+// FormResult describes the Google Form created for a contract.
+type FormResult struct {
+	FormID       string   `json:"formId"`
+	ResponderURI string   `json:"responderUri"`
+	EditURI      string   `json:"editUri"`
+	ImageFileIDs []string `json:"imageFileIds"`
+	// SignatureQuestionID is the question the client types their name into.
+	SignatureQuestionID string `json:"signatureQuestionId"`
+}
 
-	// Read the image file's content.
-	fileContent, err := ioutil.ReadFile(imgPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed while reading image file with err : %w", err)
-	}
+// SignatureResponse is the printed name a client submitted on a contract form.
+type SignatureResponse struct {
+	Name        string
+	SubmittedAt time.Time
+}
+
+func (driveService *DriveService) UploadImage(name string, content io.Reader) (*drive.File, error) {
 
 	// Create a new Google Drive file.
 	driveFile := &drive.File{
-		Name:     filepath.Base(imgPath),
-		Parents:  []string{"1UX-0xXQPRbV5aj1G_NNX06gyODgakvQP"},
+		Name:     name,
+		Parents:  []string{imagesFolderID},
 		MimeType: "image/jpeg",
 	}
 
 	// Upload the image to Google Drive.
-	uploadedFile, err := driveService.Files.Create(driveFile).Media(bytes.NewReader(fileContent)).Do()
+	uploadedFile, err := driveService.Files.Create(driveFile).Media(content).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed while creating image file in drive with err : %w", err)
 	}
@@ -80,21 +114,21 @@ func (driveService *DriveService) uploadImageToDrive(imgPath string) (*string, e
 
 	_, err = driveService.Permissions.Create(uploadedFile.Id, permission).Do()
 	if err != nil {
-		return nil, fmt.Errorf("failed while creating public permissions for the image file in drive with err : %w", err)
+		return uploadedFile, fmt.Errorf("failed while creating public permissions for the image file in drive with err : %w", err)
 	}
 
-	file, err := driveService.Files.Get(uploadedFile.Id).Fields("webContentLink").Do()
+	file, err := driveService.Files.Get(uploadedFile.Id).Fields("id, webContentLink").Do()
 	if err != nil {
-		return nil, fmt.Errorf("failed while getting the web content link for the image file in drive with err : %w", err)
+		return uploadedFile, fmt.Errorf("failed while getting the web content link for the image file in drive with err : %w", err)
 	}
 
-	return &file.WebContentLink, nil
+	return file, nil
 }
 
-func (driveService *DriveService) moveFormFileToSharedDirectory(form *forms.Form) error {
+func (driveService *DriveService) MoveFormToSharedDirectory(formID string) error {
 
 	// Retrieve the file metadata
-	formFileMetadata, err := driveService.Files.Get(form.FormId).Do()
+	formFileMetadata, err := driveService.Files.Get(formID).Fields("id, parents").Do()
 	if err != nil {
 		return fmt.Errorf("failure to get the form file metadata with error : %w", err)
 	}
@@ -110,24 +144,25 @@ func (driveService *DriveService) moveFormFileToSharedDirectory(form *forms.Form
 	return nil
 }
 
-func (driveService *DriveService) cleanUpImageFiles(parentID string) error {
-	query := fmt.Sprintf("'%s' in parents and trashed=false", parentID)
-	files, err := driveService.Files.List().Q(query).Fields("files(id, name)").Do()
+func (driveService *DriveService) RevokePublicAccess(fileID string) error {
+	permissions, err := driveService.Permissions.List(fileID).Fields("permissions(id, type)").Do()
 	if err != nil {
-		return fmt.Errorf("failure to list the files in the parent directory : %s with error : %w", parentID, err)
+		return fmt.Errorf("failed while listing the permissions of the file with ID %s with err : %w", fileID, err)
 	}
 
-	for _, file := range files.Files {
-		err := driveService.Files.Delete(file.Id).Do()
-		if err != nil {
-			return fmt.Errorf("failure to delete the file : %v with error : %w", file, err)
+	for _, permission := range permissions.Permissions {
+		if permission.Type != "anyone" {
+			continue
+		}
+		if err := driveService.Permissions.Delete(fileID, permission.Id).Do(); err != nil {
+			return fmt.Errorf("failed while removing the public permission of the file with ID %s with err : %w", fileID, err)
 		}
-
 	}
+
 	return nil
 }
 
-func (driveService *DriveService) deleteFile(fileID string) error {
+func (driveService *DriveService) DeleteFile(fileID string) error {
 	err := driveService.Files.Delete(fileID).Do()
 	if err != nil {
 		return fmt.Errorf("failed to delete the file with ID %s: %w", fileID, err)
@@ -193,9 +228,9 @@ func (formsService *FormsService) CreateImageItem(form *forms.Form, title string
 	return nil
 }
 
-func (formsService *FormsService) CreateSignatureItem(form *forms.Form, title string, index int64) error {
+func (formsService *FormsService) CreateSignatureItem(form *forms.Form, title string, index int64) (*string, error) {
 
-	_, err := formsService.Forms.BatchUpdate(form.FormId, &forms.BatchUpdateFormRequest{Requests: []*forms.Request{
+	response, err := formsService.Forms.BatchUpdate(form.FormId, &forms.BatchUpdateFormRequest{Requests: []*forms.Request{
 		{
 			CreateItem: &forms.CreateItemRequest{
 				Item: &forms.Item{
@@ -215,58 +250,143 @@ func (formsService *FormsService) CreateSignatureItem(form *forms.Form, title st
 	}},
 	).Do()
 	if err != nil {
-		return fmt.Errorf("failure to create a signature item in the form with title : %s at index %d with error : %w", title, index, err)
+		return nil, fmt.Errorf("failure to create a signature item in the form with title : %s at index %d with error : %w", title, index, err)
 	}
 
-	return nil
+	if len(response.Replies) == 0 || response.Replies[0].CreateItem == nil || len(response.Replies[0].CreateItem.QuestionId) == 0 {
+		return nil, fmt.Errorf("failure to read the question id of the signature item in the form with title : %s", title)
+	}
+
+	return &response.Replies[0].CreateItem.QuestionId[0], nil
 }
 
-func CreateGoogleForm(contract *contract.Contract) error {
-	logger := logrus.New()
-	var form *forms.Form
-	var toBeDeletedforms []*forms.Form
+// FetchSignatureResponse returns the most recent answer to the signature question of the form, or nil when the
+// client has not submitted the form yet. Forms created before the question ID was recorded match any text answer.
+func (formsService *FormsService) FetchSignatureResponse(formID string, questionID string) (*SignatureResponse, error) {
+	var latest *SignatureResponse
+	var pageToken string
 
-	ds, err := NewDriveService()
-	if err != nil {
-		return fmt.Errorf("failure to a new drive service with error : %w", err)
+	for {
+		call := formsService.Forms.Responses.List(formID)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		responses, err := call.Do()
+		if err != nil {
+			return nil, fmt.Errorf("failure to list the responses of the form %s with error : %w", formID, err)
+		}
+
+		for _, response := range responses.Responses {
+			name := signatureAnswer(response, questionID)
+			if name == "" {
+				continue
+			}
+
+			submittedAt, err := time.Parse(time.RFC3339Nano, response.LastSubmittedTime)
+			if err != nil {
+				return nil, fmt.Errorf("failure to parse the submission time %s of the form %s with error : %w", response.LastSubmittedTime, formID, err)
+			}
+
+			if latest == nil || submittedAt.After(latest.SubmittedAt) {
+				latest = &SignatureResponse{Name: name, SubmittedAt: submittedAt}
+			}
+		}
+
+		pageToken = responses.NextPageToken
+		if pageToken == "" {
+			return latest, nil
+		}
 	}
+}
 
-	fs, err := NewFormsService()
-	if err != nil {
-		return fmt.Errorf("failure to a new forms service with error : %w", err)
+func signatureAnswer(response *forms.FormResponse, questionID string) string {
+	for id, answer := range response.Answers {
+		if questionID != "" && id != questionID {
+			continue
+		}
+		if answer.TextAnswers == nil {
+			continue
+		}
+		for _, textAnswer := range answer.TextAnswers.Answers {
+			if value := strings.TrimSpace(textAnswer.Value); value != "" {
+				return value
+			}
+		}
 	}
+	return ""
+}
+
+// FormResponderURL is the link a client opens to view and sign the form.
+func FormResponderURL(formID string) string {
+	return fmt.Sprintf("https://docs.google.com/forms/d/%s/viewform", formID)
+}
+
+// FormEditURL is the link staff open to edit the form.
+func FormEditURL(formID string) string {
+	return fmt.Sprintf("https://docs.google.com/forms/d/%s/edit", formID)
+}
+
+// CreateGoogleForm creates a form showing one image per page of the contract followed by the signature question.
+// The sessions of split events and the payment schedule are repeated in the form description.
+func CreateGoogleForm(ds FileStore, fs FormBuilder, contract *contract.Contract, schedule *pricing.Schedule, images [][]byte) (*FormResult, error) {
+	logger := logrus.New()
+	var form *forms.Form
+	var toBeDeletedforms []*forms.Form
+	var uploadedFiles []*drive.File
+	var err error
 
-	formTitle := "RED DOT STUDIOS SERVICES AGREEMENT"
-	formDescription := fmt.Sprintf("This Agreement was made and entered into on %s between Red Dot Studios, a Massachusetts based photography and videography Service and %s(\"Client\").", time.Now().Format("01/02/2006"), contract.ClientDetails.ClientName)
+	brand := branding.Current()
+	formTitle := fmt.Sprintf("%s SERVICES AGREEMENT", strings.ToUpper(brand.Name))
+	formDescription := fmt.Sprintf("This Agreement was made and entered into on %s between %s, a %s based %s Service and %s(\"Client\"). %s%s", time.Now().Format("01/02/2006"), brand.LegalName, brand.Location, brand.Services, contract.ClientDetails.ClientName, sessionsSummary(contract.EventDetails), schedule.Summary())
+
+	imageName := fmt.Sprintf("image-contract-%s-%s", strings.ReplaceAll(contract.EventDetails.EventName, " ", "_"), strings.ReplaceAll(contract.ClientDetails.ClientName, " ", "_"))
+	pageImages := make([]*drive.File, len(images))
+	var signatureQuestionID *string
+	var jobComplete bool
+	var retryCount int
 
 	defer func() {
 		if err != nil {
 			logger.WithError(err).Errorf("Deleting the form since the error was not nil")
-			for _, toBeDeletedform := range toBeDeletedforms {
-				ds.deleteFile(toBeDeletedform.FormId)
+		}
+		for _, toBeDeletedform := range toBeDeletedforms {
+			ds.DeleteFile(toBeDeletedform.FormId)
+		}
+
+		// Only the images the final form was built from are kept, and only staff can read them once the form has
+		// copied them. An image that cannot be made private again is deleted rather than left public.
+		for _, uploadedFile := range uploadedFiles {
+			if err == nil && isPageImage(uploadedFile, pageImages) {
+				revokeErr := ds.RevokePublicAccess(uploadedFile.Id)
+				if revokeErr == nil {
+					continue
+				}
+				logger.WithError(revokeErr).WithField("fileId", uploadedFile.Id).Errorf("failed to make the page image private, deleting it")
 			}
+			ds.DeleteFile(uploadedFile.Id)
 		}
-		ds.cleanUpImageFiles("1UX-0xXQPRbV5aj1G_NNX06gyODgakvQP")
 	}()
 
-	var jobComplete bool
-	var retryCount int
-
+attempts:
 	for retryCount < 3 && !jobComplete {
-		contractsImageURL, err := ds.uploadImageToDrive("img/image-contract.jpg")
-		if err != nil {
-			retryCount++
-			logger.WithField("retryCount", retryCount).WithError(err).Errorf("failed to upload image to drive, retrying ...")
-			time.Sleep(5)
-			continue
-		}
+		for page, image := range images {
+			if pageImages[page] != nil {
+				continue
+			}
 
-		termsImageURL, err := ds.uploadImageToDrive("img/image-terms.jpg")
-		if err != nil {
-			retryCount++
-			logger.WithField("retryCount", retryCount).WithError(err).Errorf("failed to upload image to drive, retrying ...")
-			time.Sleep(5)
-			continue
+			uploadedFile, uploadErr := ds.UploadImage(fmt.Sprintf("%s-%d.jpg", imageName, page+1), bytes.NewReader(image))
+			if uploadedFile != nil {
+				uploadedFiles = append(uploadedFiles, uploadedFile)
+			}
+			if uploadErr != nil {
+				err = uploadErr
+				retryCount++
+				logger.WithField("retryCount", retryCount).WithError(err).Errorf("failed to upload image to drive, retrying ...")
+				time.Sleep(5)
+				continue attempts
+			}
+			pageImages[page] = uploadedFile
 		}
 
 		form, err = fs.CreateFormWithTitleAndDescription(contract, &formTitle, &formDescription)
@@ -277,25 +397,19 @@ func CreateGoogleForm(contract *contract.Contract) error {
 			continue
 		}
 
-		err = fs.CreateImageItem(form, "The Client hereby agree as follows:", contractsImageURL, 0)
-		if err != nil {
-			toBeDeletedforms = append(toBeDeletedforms, form)
-			retryCount++
-			logger.WithField("retryCount", retryCount).WithError(err).Errorf("failed to create image item, retrying ...")
-			time.Sleep(5)
-			continue
-		}
-
-		err = fs.CreateImageItem(form, "Terms and Conditions", termsImageURL, 1)
-		if err != nil {
-			toBeDeletedforms = append(toBeDeletedforms, form)
-			retryCount++
-			logger.WithField("retryCount", retryCount).WithError(err).Errorf("failed to create image item, retrying ...")
-			time.Sleep(5)
-			continue
+		for page, pageImage := range pageImages {
+			title := fmt.Sprintf("Page %d of %d", page+1, len(pageImages))
+			err = fs.CreateImageItem(form, title, &pageImage.WebContentLink, int64(page))
+			if err != nil {
+				toBeDeletedforms = append(toBeDeletedforms, form)
+				retryCount++
+				logger.WithField("retryCount", retryCount).WithError(err).Errorf("failed to create image item, retrying ...")
+				time.Sleep(5)
+				continue attempts
+			}
 		}
 
-		err = fs.CreateSignatureItem(form, "Digital Signature (Printed Name):", 2)
+		signatureQuestionID, err = fs.CreateSignatureItem(form, "Digital Signature (Printed Name):", int64(len(pageImages)))
 		if err != nil {
 			toBeDeletedforms = append(toBeDeletedforms, form)
 			retryCount++
@@ -307,13 +421,49 @@ func CreateGoogleForm(contract *contract.Contract) error {
 		jobComplete = true
 	}
 	if err != nil {
-		return fmt.Errorf("failure while creating form item with error : %w", err)
+		return nil, fmt.Errorf("failure while creating form item with error : %w", err)
 	}
 
-	err = ds.moveFormFileToSharedDirectory(form)
+	err = ds.MoveFormToSharedDirectory(form.FormId)
 	if err != nil {
-		return fmt.Errorf("failure to move form file to shared directory with error : %w", err)
+		toBeDeletedforms = append(toBeDeletedforms, form)
+		return nil, fmt.Errorf("failure to move form file to shared directory with error : %w", err)
 	}
 
-	return nil
+	responderURI := form.ResponderUri
+	if responderURI == "" {
+		responderURI = FormResponderURL(form.FormId)
+	}
+
+	imageFileIDs := make([]string, 0, len(pageImages))
+	for _, pageImage := range pageImages {
+		imageFileIDs = append(imageFileIDs, pageImage.Id)
+	}
+
+	return &FormResult{
+		FormID:              form.FormId,
+		ResponderURI:        responderURI,
+		EditURI:             FormEditURL(form.FormId),
+		ImageFileIDs:        imageFileIDs,
+		SignatureQuestionID: *signatureQuestionID,
+	}, nil
+}
+
+func isPageImage(file *drive.File, pageImages []*drive.File) bool {
+	for _, pageImage := range pageImages {
+		if pageImage == file {
+			return true
+		}
+	}
+	return false
+}
+
+// sessionsSummary lists the sessions of an event split over several days or venues in a sentence each, empty for
+// events that are not split.
+func sessionsSummary(event contract.EventDetails) string {
+	summary := ""
+	for _, session := range event.Sessions {
+		summary += fmt.Sprintf("%s: %s, %s at %s. ", session.Name, session.Date, session.CoverageTime.Format(session.Date), session.Venue)
+	}
+	return summary
 }
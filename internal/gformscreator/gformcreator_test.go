@@ -0,0 +1,124 @@
+package gformscreator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/contract"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/money"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/pricing"
+	"google.golang.org/api/forms/v1"
+)
+
+func testContract(t *testing.T) (*contract.Contract, *pricing.Schedule) {
+	t.Helper()
+
+	details := &contract.Contract{
+		ClientDetails: contract.ClientDetails{ClientName: "Priya Raman"},
+		EventDetails:  contract.EventDetails{EventName: "House Warming"},
+		PaymentDetails: contract.PaymentDetails{
+			TotalAmount:  money.FromMajor(1500, money.DefaultCurrency),
+			PerHourExtra: money.FromMajor(150, money.DefaultCurrency),
+		},
+	}
+	schedule, err := pricing.NewSchedule(details.PaymentDetails)
+	if err != nil {
+		t.Fatalf("NewSchedule : %v", err)
+	}
+	return details, schedule
+}
+
+// failingImageItems is a FakeFormBuilder that cannot add images to the forms it creates.
+type failingImageItems struct {
+	*FakeFormBuilder
+}
+
+func (builder failingImageItems) CreateImageItem(form *forms.Form, title string, sourceURI *string, index int64) error {
+	return errors.New("image items are not available")
+}
+
+func TestCreateGoogleForm(t *testing.T) {
+	files, builder := NewFakeFileStore(), NewFakeFormBuilder()
+	details, schedule := testContract(t)
+
+	result, err := CreateGoogleForm(files, builder, details, schedule, [][]byte{[]byte("page one"), []byte("page two")})
+	if err != nil {
+		t.Fatalf("CreateGoogleForm : %v", err)
+	}
+
+	fakeForm, ok := builder.Forms[result.FormID]
+	if !ok || len(builder.Forms) != 1 {
+		t.Fatalf("form %s was not created, %d forms were", result.FormID, len(builder.Forms))
+	}
+	if fakeForm.Form.Info.Title != "RED DOT STUDIOS SERVICES AGREEMENT" || !strings.Contains(fakeForm.Form.Info.Description, "Priya Raman") {
+		t.Errorf("form is titled %q with description %q", fakeForm.Form.Info.Title, fakeForm.Form.Info.Description)
+	}
+	if result.ResponderURI != fakeForm.Form.ResponderUri || result.EditURI != FormEditURL(result.FormID) {
+		t.Errorf("form links = %q and %q", result.ResponderURI, result.EditURI)
+	}
+
+	// One page per image in order, then the signature question
+	if len(fakeForm.Items) != 3 || fakeForm.Items[0].Title != "Page 1 of 2" || fakeForm.Items[1].Title != "Page 2 of 2" {
+		t.Fatalf("form items = %+v", fakeForm.Items)
+	}
+	if question := fakeForm.Items[2].QuestionItem; question == nil || question.Question.QuestionId != result.SignatureQuestionID {
+		t.Errorf("last item is not the signature question %s", result.SignatureQuestionID)
+	}
+
+	if len(result.ImageFileIDs) != 2 || len(files.Files) != 2 {
+		t.Fatalf("%d image IDs returned for %d kept files", len(result.ImageFileIDs), len(files.Files))
+	}
+	for page, fileID := range result.ImageFileIDs {
+		file := files.Files[fileID]
+		if file == nil || file.Public || !strings.HasSuffix(file.Name, fmt.Sprintf("-%d.jpg", page+1)) {
+			t.Errorf("page %d image = %+v", page+1, file)
+		}
+	}
+	if len(files.SharedForms) != 1 || files.SharedForms[0] != result.FormID {
+		t.Errorf("shared forms = %v", files.SharedForms)
+	}
+}
+
+func TestCreateGoogleFormFailure(t *testing.T) {
+	files, builder := NewFakeFileStore(), NewFakeFormBuilder()
+	details, schedule := testContract(t)
+
+	// Every form is deleted along with the images once the retries run out
+	_, err := CreateGoogleForm(files, failingImageItems{builder}, details, schedule, [][]byte{[]byte("page one")})
+	if err == nil {
+		t.Fatal("CreateGoogleForm did not fail")
+	}
+	if len(files.Files) != 0 || len(files.SharedForms) != 0 {
+		t.Errorf("%d files were kept and %d forms shared", len(files.Files), len(files.SharedForms))
+	}
+	for formID := range builder.Forms {
+		if !contains(files.Deleted, formID) {
+			t.Errorf("form %s was not deleted", formID)
+		}
+	}
+}
+
+func TestSignatureAnswer(t *testing.T) {
+	response := &forms.FormResponse{Answers: map[string]forms.Answer{
+		"other":     {TextAnswers: &forms.TextAnswers{Answers: []*forms.TextAnswer{{Value: "Boston"}}}},
+		"signature": {TextAnswers: &forms.TextAnswers{Answers: []*forms.TextAnswer{{Value: " "}, {Value: " Priya Raman "}}}},
+	}}
+
+	if got := signatureAnswer(response, "signature"); got != "Priya Raman" {
+		t.Errorf("signature answer = %q", got)
+	}
+	if got := signatureAnswer(response, "missing"); got != "" {
+		t.Errorf("answer of a missing question = %q", got)
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -1,62 +1,38 @@
 package imagecreator
 
 import (
+	"bytes"
 	"fmt"
 	"image/jpeg"
-	"os"
-	"path/filepath"
 
 	"github.com/karmdip-mi/go-fitz"
 )
 
-type ImageType string
-
-const (
-	Contract ImageType = "contract"
-	Terms              = "terms"
-)
-
-func ImageCreator(imageType ImageType, fileName *string) error {
-	doc, err := fitz.New(*fileName + ".pdf")
+// ImageCreator renders every page of the pdf as a jpeg and returns them in page order.
+// Everything is kept in memory so concurrent requests never share files.
+func ImageCreator(document []byte) ([][]byte, error) {
+	doc, err := fitz.NewFromMemory(document)
 	if err != nil {
-		return fmt.Errorf("failed while creating new pdf to image doc with error : %w", err)
+		return nil, fmt.Errorf("failed while creating new pdf to image doc with error : %w", err)
 	}
+	defer doc.Close()
 
 	// Extract pages as images
+	var images [][]byte
 	for n := 0; n < doc.NumPage(); n++ {
 		img, err := doc.Image(n)
 		if err != nil {
-			return fmt.Errorf("failed while creating image out of pdf page with error : %w", err)
-		}
-
-		err = os.MkdirAll("img", 0755)
-		if err != nil {
-			return fmt.Errorf("failed while creating img directory with error : %w", err)
-		}
-
-		f, err := os.Create(filepath.Join("img/", fmt.Sprintf("image-%s.jpg", imageType)))
-		if err != nil {
-			return fmt.Errorf("failed while creating image file with error : %w", err)
+			return nil, fmt.Errorf("failed while creating image out of pdf page with error : %w", err)
 		}
 
-		err = jpeg.Encode(f, img, &jpeg.Options{Quality: 10})
+		var image bytes.Buffer
+		err = jpeg.Encode(&image, img, &jpeg.Options{Quality: 10})
 		if err != nil {
-			if err != nil {
-				return fmt.Errorf("failed while jpeg encoding image with error : %w", err)
-			}
+			return nil, fmt.Errorf("failed while jpeg encoding image with error : %w", err)
 		}
 
-		f.Close()
-
+		images = append(images, image.Bytes())
 	}
-	return nil
 
-}
-
-func CleanUpImages() error {
-	err := os.RemoveAll("img")
-	if err != nil {
-		return fmt.Errorf("failed while trying to clean up images in /img directory with error : %w", err)
-	}
-	return nil
+	return images, nil
 }
@@ -0,0 +1,274 @@
+package money
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the currency of amounts that do not name one.
+const DefaultCurrency = "USD"
+
+var (
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrCurrencyMismatch = errors.New("currencies do not match")
+	ErrInvalidAmount    = errors.New("invalid amount")
+)
+
+// currency is how amounts in a currency are written.
+type currency struct {
+	// Digits is the number of minor unit digits.
+	Digits int
+	Symbol string
+	// Decimal and Group separate the minor units and groups of thousands.
+	Decimal string
+	Group   string
+	// SymbolAfter writes the symbol after the amount, as in "1 249,50 €".
+	SymbolAfter bool
+}
+
+// currencies are the currencies contracts can be booked in.
+var currencies = map[string]currency{
+	"USD": {Digits: 2, Symbol: "$", Decimal: ".", Group: ","},
+	"CAD": {Digits: 2, Symbol: "CA$", Decimal: ".", Group: ","},
+	"EUR": {Digits: 2, Symbol: "€", Decimal: ",", Group: ".", SymbolAfter: true},
+	"GBP": {Digits: 2, Symbol: "£", Decimal: ".", Group: ","},
+}
+
+// Money is an amount in the minor units of an ISO 4217 currency, cents for dollars.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency,omitempty"`
+}
+
+// New returns an amount of minor units.
+func New(minor int64, currencyCode string) Money {
+	return Money{Amount: minor, Currency: currencyCode}
+}
+
+// FromMajor returns an amount of whole units, dollars for dollars.
+func FromMajor(major int64, currencyCode string) Money {
+	return Money{Amount: major * scale(currencyCode), Currency: currencyCode}
+}
+
+// Supported reports whether amounts can be booked in the currency.
+func Supported(currencyCode string) bool {
+	_, ok := currencies[currencyCode]
+	return ok
+}
+
+// Parse reads a decimal amount in whole units, such as "1249.50" or "1,249.50", without going through floats.
+func Parse(value string, currencyCode string) (Money, error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), ",", "")
+	if value == "" {
+		return Money{}, fmt.Errorf("%w : amount is empty", ErrInvalidAmount)
+	}
+
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(value, "-")
+
+	// The leading minus is the only sign, the rest is digits with at most one decimal point
+	if strings.Trim(value, "0123456789.") != "" || strings.Count(value, ".") > 1 || strings.Trim(value, ".") == "" {
+		return Money{}, fmt.Errorf("%w : %s is not an amount", ErrInvalidAmount, value)
+	}
+
+	whole, fraction := value, ""
+	if dot := strings.IndexByte(value, '.'); dot >= 0 {
+		whole, fraction = value[:dot], value[dot+1:]
+	}
+
+	digits := currencyDigits(currencyCode)
+	if len(fraction) > digits {
+		return Money{}, fmt.Errorf("%w : %s has more than %d decimal places", ErrInvalidAmount, value, digits)
+	}
+	fraction += strings.Repeat("0", digits-len(fraction))
+	if whole == "" {
+		whole = "0"
+	}
+
+	minor, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w : %s", ErrInvalidAmount, value)
+	}
+	if negative {
+		minor = -minor
+	}
+
+	return Money{Amount: minor, Currency: currencyCode}, nil
+}
+
+// UnmarshalJSON accepts an {"amount", "currency"} object in minor units, or a plain number or string in whole
+// units for clients that only send dollars. Plain amounts are left without a currency for their owner to fill in.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		return nil
+	case len(data) > 0 && data[0] == '{':
+		type plain Money
+		return json.Unmarshal(data, (*plain)(m))
+	case len(data) > 0 && data[0] == '"':
+		var value string
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		parsed, err := Parse(value, "")
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	default:
+		parsed, err := Parse(string(data), "")
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	}
+}
+
+// Or fills in the currency of an amount that does not have one yet.
+func (m Money) Or(currencyCode string) Money {
+	if m.Currency == "" {
+		m.Currency = currencyCode
+	}
+	return m
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// SameCurrency reports whether both amounts are in the same currency, amounts without one match any currency.
+func (m Money) SameCurrency(other Money) bool {
+	return m.Currency == "" || other.Currency == "" || m.Currency == other.Currency
+}
+
+// Add returns the sum of both amounts. Amounts are checked to be in the contract's currency as they come in, so
+// adding up amounts in different currencies is a bug and panics with ErrCurrencyMismatch.
+func (m Money) Add(other Money) Money {
+	m.mustMatch(other)
+	return Money{Amount: m.Amount + other.Amount, Currency: m.code(other)}
+}
+
+// Sub returns the difference of both amounts and panics with ErrCurrencyMismatch like Add.
+func (m Money) Sub(other Money) Money {
+	m.mustMatch(other)
+	return Money{Amount: m.Amount - other.Amount, Currency: m.code(other)}
+}
+
+// Cmp returns -1, 0 or 1 when the amount is less than, equal to or greater than the other one.
+func (m Money) Cmp(other Money) int {
+	m.mustMatch(other)
+	switch {
+	case m.Amount < other.Amount:
+		return -1
+	case m.Amount > other.Amount:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Min returns the smaller of the two amounts.
+func (m Money) Min(other Money) Money {
+	m.mustMatch(other)
+	if other.Amount < m.Amount {
+		return Money{Amount: other.Amount, Currency: m.code(other)}
+	}
+	return Money{Amount: m.Amount, Currency: m.code(other)}
+}
+
+// Share is the given share of the amount in basis points, 2500 for 25%, rounded half up to the minor unit.
+func (m Money) Share(basisPoints int64) Money {
+	product := m.Amount * basisPoints
+	share := product / 10000
+	if remainder := product % 10000; remainder*2 >= 10000 {
+		share++
+	} else if remainder*2 <= -10000 {
+		share--
+	}
+	return Money{Amount: share, Currency: m.Currency}
+}
+
+// RoundUpTo rounds the amount up to the next multiple of step, leaving it unchanged when step is not positive.
+func (m Money) RoundUpTo(step Money) Money {
+	m.mustMatch(step)
+	if step.Amount <= 0 || m.Amount%step.Amount == 0 {
+		return m
+	}
+	return Money{Amount: (m.Amount/step.Amount + 1) * step.Amount, Currency: m.code(step)}
+}
+
+// BasisPoints converts a percent such as 25 or 12.5 to basis points, the only place percents meet floats.
+func BasisPoints(percent float64) int64 {
+	return int64(math.Round(percent * 100))
+}
+
+// String formats the amount the way it is written in its currency, as in "$1,249.50" or "CA$1,249.50".
+func (m Money) String() string {
+	format, ok := currencies[m.Currency]
+	if !ok {
+		format = currencies[DefaultCurrency]
+	}
+
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	unit := scale(m.Currency)
+	whole := strconv.FormatInt(amount/unit, 10)
+	for i := len(whole) - 3; i > 0; i -= 3 {
+		whole = whole[:i] + format.Group + whole[i:]
+	}
+
+	number := whole
+	if format.Digits > 0 {
+		number += format.Decimal + fmt.Sprintf("%0*d", format.Digits, amount%unit)
+	}
+
+	if format.SymbolAfter {
+		return sign + number + " " + format.Symbol
+	}
+	return sign + format.Symbol + number
+}
+
+func (m Money) mustMatch(other Money) {
+	if !m.SameCurrency(other) {
+		panic(fmt.Errorf("%w : %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency))
+	}
+}
+
+func (m Money) code(other Money) string {
+	if m.Currency != "" {
+		return m.Currency
+	}
+	return other.Currency
+}
+
+func currencyDigits(currencyCode string) int {
+	if format, ok := currencies[currencyCode]; ok {
+		return format.Digits
+	}
+	return currencies[DefaultCurrency].Digits
+}
+
+func scale(currencyCode string) int64 {
+	unit := int64(1)
+	for i := 0; i < currencyDigits(currencyCode); i++ {
+		unit *= 10
+	}
+	return unit
+}
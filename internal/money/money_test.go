@@ -0,0 +1,125 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestShare(t *testing.T) {
+	tests := []struct {
+		amount      int64
+		basisPoints int64
+		want        int64
+	}{
+		{150000, 2500, 37500},
+		{57050, 625, 3566}, // 35.65625 rounds up
+		{57040, 625, 3565}, // 35.65 exactly
+		{1, 5000, 1},       // half a cent rounds up
+		{-1, 5000, -1},     // and away from zero for refunds
+		{99999, 0, 0},
+	}
+
+	for _, test := range tests {
+		if got := New(test.amount, "USD").Share(test.basisPoints); got.Amount != test.want {
+			t.Errorf("%d basis points of %d = %d, want %d", test.basisPoints, test.amount, got.Amount, test.want)
+		}
+	}
+
+	if got := BasisPoints(6.25); got != 625 {
+		t.Errorf("BasisPoints(6.25) = %d", got)
+	}
+}
+
+func TestRoundUpTo(t *testing.T) {
+	ten := FromMajor(10, "USD")
+	if got := New(37500, "USD").RoundUpTo(ten); got.Amount != 38000 {
+		t.Errorf("375.00 rounded up to 10 = %s", got)
+	}
+	if got := New(38000, "USD").RoundUpTo(ten); got.Amount != 38000 {
+		t.Errorf("380.00 rounded up to 10 = %s", got)
+	}
+	if got := New(37550, "USD").RoundUpTo(New(0, "USD")); got.Amount != 37550 {
+		t.Errorf("rounding up to nothing changed the amount to %s", got)
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{New(124950, "USD"), "$1,249.50"},
+		{New(124950, "CAD"), "CA$1,249.50"},
+		{New(124950, "EUR"), "1.249,50 €"},
+		{New(-500, "GBP"), "-£5.00"},
+		{New(100000000, ""), "$1,000,000.00"},
+	}
+
+	for _, test := range tests {
+		if got := test.money.String(); got != test.want {
+			t.Errorf("%+v is written as %q, want %q", test.money, got, test.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	got, err := Parse("1249.5", "USD")
+	if err != nil || got != New(124950, "USD") {
+		t.Errorf("Parse(1249.5) = %+v, %v", got, err)
+	}
+	if _, err := Parse("12.345", "USD"); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("Parse of fractions of a cent returned %v, want ErrInvalidAmount", err)
+	}
+	if got, err := Parse("-12.50", "USD"); err != nil || got != New(-1250, "USD") {
+		t.Errorf("Parse(-12.50) = %+v, %v", got, err)
+	}
+	for _, value := range []string{"--5", "-+5", "+5", "5-", "1.2.3", ".", "-", "5 USD"} {
+		if got, err := Parse(value, "USD"); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("Parse(%q) = %+v, %v, want ErrInvalidAmount", value, got, err)
+		}
+	}
+
+	// Plain numbers are whole units left for the contract to put in its currency
+	var amounts []Money
+	if err := json.Unmarshal([]byte(`[1500, "1,249.50", {"amount": 5000, "currency": "EUR"}]`), &amounts); err != nil {
+		t.Fatalf("Unmarshal : %v", err)
+	}
+	want := []Money{New(150000, ""), New(124950, ""), New(5000, "EUR")}
+	for i := range want {
+		if amounts[i] != want[i] {
+			t.Errorf("amount %d = %+v, want %+v", i+1, amounts[i], want[i])
+		}
+	}
+	if Supported("XYZ") {
+		t.Error("XYZ is supported")
+	}
+}
+
+func TestCurrencyMismatch(t *testing.T) {
+	dollars, euros := FromMajor(10, "USD"), FromMajor(10, "EUR")
+
+	// Amounts without a currency yet take the currency of the other amount
+	if got := New(0, "").Add(dollars); got != dollars {
+		t.Errorf("adding to nothing = %+v", got)
+	}
+
+	operations := map[string]func(){
+		"Add":       func() { dollars.Add(euros) },
+		"Sub":       func() { dollars.Sub(euros) },
+		"Cmp":       func() { dollars.Cmp(euros) },
+		"Min":       func() { dollars.Min(euros) },
+		"RoundUpTo": func() { dollars.RoundUpTo(euros) },
+	}
+	for name, operation := range operations {
+		func() {
+			defer func() {
+				err, _ := recover().(error)
+				if !errors.Is(err, ErrCurrencyMismatch) {
+					t.Errorf("%s of dollars and euros panicked with %v, want ErrCurrencyMismatch", name, err)
+				}
+			}()
+			operation()
+		}()
+	}
+}
@@ -0,0 +1,120 @@
+package pdfcreator
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/johnfercher/maroto/pkg/consts"
+	"github.com/johnfercher/maroto/pkg/pdf"
+	"github.com/johnfercher/maroto/pkg/props"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/branding"
+)
+
+const (
+	// headerHeight is how tall the studio details at the top of every page are.
+	headerHeight = 20
+	// footerHeight is how tall the contract ID and page number at the bottom of every page are.
+	footerHeight = 10
+	// footerSpace is the space above the rule of the footer.
+	footerSpace = 4
+	// totalPagesAlias is replaced with the number of pages once the document is done.
+	totalPagesAlias = "{nb}"
+)
+
+// newDocument starts a Letter document in the studio's branding, with its details at the top of every page and the
+// contract the document belongs to and the page number at the bottom.
+func newDocument(contractID string, brand branding.Branding) (pdf.Maroto, *textLayout) {
+	document := pdf.NewMaroto(consts.Portrait, consts.Letter)
+	document.SetPageMargins(10, 10, 10)
+	document.SetFontLocation(branding.FontLocation)
+	for style, file := range brand.Fonts.Files() {
+		document.AddUTF8Font(branding.FontFamily, style, file)
+	}
+	if brand.Fonts.Regular != "" {
+		document.SetDefaultFontFamily(branding.FontFamily)
+	}
+	document.SetFirstPageNb(1)
+	document.SetAliasNbPages(totalPagesAlias)
+
+	document.RegisterHeader(func() {
+		writePageHeader(document, brand)
+	})
+	document.RegisterFooter(func() {
+		writePageFooter(document, brand, contractID)
+	})
+
+	return document, newTextLayout(document, brand)
+}
+
+// writePageHeader prints the logo, or the studio name when there is none, with the studio's address and contact
+// details across from it. Pages can break in the middle of a table, so the borders of its rows are turned off for
+// the header and back on after it.
+func writePageHeader(document pdf.Maroto, brand branding.Branding) {
+	border := document.GetBorder()
+	document.SetBorder(false)
+	defer document.SetBorder(border)
+
+	logo, format := brand.LogoImage()
+
+	var details []string
+	if logo != "" {
+		details = append(details, brand.LegalName)
+	}
+	if brand.Address != "" {
+		details = append(details, brand.Address)
+	} else {
+		details = append(details, fmt.Sprintf("%s, %s", strings.ToUpper(brand.Services[:1])+brand.Services[1:], brand.Location))
+	}
+	if contact := brand.Contact(); contact != "" {
+		details = append(details, contact)
+	} else {
+		details = append(details, fmt.Sprintf("Zelle: %s", brand.Zelle))
+	}
+
+	document.Row(headerHeight-6, func() {
+		document.Col(4, func() {
+			if logo != "" {
+				// The logo was checked when the branding was loaded
+				_ = document.Base64Image(logo, format, props.Rect{Percent: 100})
+				return
+			}
+			document.Text(brand.Name, props.Text{Top: 3, Style: consts.Bold, Size: 16, Color: brand.Accent(), Extrapolate: true})
+		})
+		document.Col(8, func() {
+			for i, detail := range details {
+				style := consts.Normal
+				if logo != "" && i == 0 {
+					style = consts.Bold
+				}
+				document.Text(detail, props.Text{Top: 1 + 4*float64(i), Style: style, Size: 8, Align: consts.Right})
+			}
+		})
+	})
+	document.Line(6, props.Line{Color: brand.Accent(), Width: 0.5})
+}
+
+// writePageFooter prints the contract ID and the page number below a rule. Maroto rounds the offset down as it
+// moves to the footer, so the space above the rule gives back what was rounded off to keep the footer on the page.
+// Like the header, it is printed without the borders of the table the page breaks in.
+func writePageFooter(document pdf.Maroto, brand branding.Branding, contractID string) {
+	border := document.GetBorder()
+	document.SetBorder(false)
+	defer document.SetBorder(border)
+
+	_, page := pageSpace(document)
+	overflow := math.Max(0, document.GetCurrentOffset()-(page+headerHeight))
+
+	document.Row(footerSpace-math.Min(overflow, footerSpace), func() {
+		document.ColSpace(uint(consts.MaxGridSum))
+	})
+	document.Line(1, props.Line{Color: brand.Accent(), Width: 0.3})
+	document.Row(footerHeight-footerSpace-1, func() {
+		document.Col(6, func() {
+			document.Text(fmt.Sprintf("Contract #%s", contractID), props.Text{Top: 1.5, Size: 8})
+		})
+		document.Col(6, func() {
+			document.Text(fmt.Sprintf("Page %d of %s", document.GetCurrentPage(), totalPagesAlias), props.Text{Top: 1.5, Size: 8, Align: consts.Right})
+		})
+	})
+}
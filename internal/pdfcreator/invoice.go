@@ -0,0 +1,220 @@
+package pdfcreator
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/johnfercher/maroto/pkg/consts"
+	"github.com/johnfercher/maroto/pkg/pdf"
+	"github.com/johnfercher/maroto/pkg/props"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/branding"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/contract"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/pricing"
+)
+
+// documentDateLayout is how dates are printed on invoices and receipts.
+const documentDateLayout = "01/02/2006"
+
+// RenderInvoice renders the invoice of a contract, listing its payment schedule and the balance due as the invoice
+// was last brought up to date.
+func RenderInvoice(record *contract.Record) ([]byte, error) {
+	invoice := record.Invoice
+	if invoice == nil {
+		return nil, fmt.Errorf("could not render invoice with error : contract %s has not been invoiced", record.ID)
+	}
+
+	document, layout := newDocument(record.ID, branding.IssuedBy(record.Studio))
+
+	writeDocumentHeader(document, "INVOICE", [][]string{
+		{"Invoice No.", record.InvoiceNumber},
+		{"Issued on", invoice.IssuedAt.Format(documentDateLayout)},
+	})
+	writeParties(document, record, "Bill To")
+
+	document.SetBorder(true)
+	document.Row(6, func() {
+		document.Col(1, func() {
+			document.Text("S.No", props.Text{Top: 1, Align: consts.Center})
+		})
+		document.Col(5, func() {
+			document.Text("Description", props.Text{Top: 1, Align: consts.Center})
+		})
+		document.Col(2, func() {
+			document.Text("Due", props.Text{Top: 1, Align: consts.Center})
+		})
+		document.Col(2, func() {
+			document.Text("Amount", props.Text{Top: 1, Align: consts.Center})
+		})
+		document.Col(2, func() {
+			document.Text("Received", props.Text{Top: 1, Align: consts.Center})
+		})
+	})
+
+	for i, line := range invoice.Lines {
+		height := math.Max(6, 2+math.Max(
+			layout.textHeight(line.Description, consts.Normal, 0, 5, 2),
+			layout.textHeight(line.Due, consts.Normal, 8, 2, 0),
+		))
+		keepTogether(document, height)
+
+		document.Row(height, func() {
+			document.Col(1, func() {
+				document.Text(fmt.Sprintf("%d.", i+1), props.Text{Top: 1, Align: consts.Center})
+			})
+			document.Col(5, func() {
+				document.Text(line.Description, props.Text{Top: 1, Left: 2, Align: consts.Left})
+			})
+			document.Col(2, func() {
+				document.Text(line.Due, props.Text{Top: 1, Align: consts.Center, Size: 8})
+			})
+			document.Col(2, func() {
+				document.Text(line.Amount.String(), props.Text{Top: 1, Right: 2, Align: consts.Right})
+			})
+			document.Col(2, func() {
+				document.Text(line.Received.String(), props.Text{Top: 1, Right: 2, Align: consts.Right})
+			})
+		})
+	}
+	document.SetBorder(false)
+
+	writeTotals(document, [][]string{
+		{"Total", invoice.Total.String()},
+		{"Received", invoice.Received.String()},
+		{"Balance Due", invoice.BalanceDue.String()},
+	})
+
+	writeDocumentNote(layout, fmt.Sprintf("Payments can be made through Zelle to %s. Remaining project payments are accepted in cash only, on the day of the event.", branding.Current().Zelle))
+
+	output, err := document.Output()
+	if err != nil {
+		return nil, fmt.Errorf("could not render invoice with error : %w", err)
+	}
+
+	return output.Bytes(), nil
+}
+
+// RenderReceipt renders the receipt of a payment. The schedule should only have the payments received up to and
+// including this one applied, so the balance shown is the one left right after it.
+func RenderReceipt(record *contract.Record, entry *contract.LedgerEntry, schedule *pricing.Schedule) ([]byte, error) {
+	document, layout := newDocument(record.ID, branding.IssuedBy(record.Studio))
+
+	writeDocumentHeader(document, "PAYMENT RECEIPT", [][]string{
+		{"Receipt No.", entry.ReceiptNumber},
+		{"Received on", entry.ReceivedAt.Format(documentDateLayout)},
+	})
+	writeParties(document, record, "Received From")
+
+	reference := entry.Reference
+	if reference == "" {
+		reference = "-"
+	}
+
+	document.SetBorder(true)
+	for _, row := range [][]string{
+		{"Amount received", entry.Amount.String()},
+		{"Payment mode", entry.Mode},
+		{"Reference", reference},
+		{"Recorded by", entry.RecordedBy},
+	} {
+		label, value := row[0], row[1]
+		height := math.Max(8, 4+layout.textHeight(value, consts.Normal, 0, 8, 2))
+		document.Row(height, func() {
+			document.Col(4, func() {
+				document.Text(label, props.Text{Top: 2, Left: 2, Style: consts.Bold})
+			})
+			document.Col(8, func() {
+				document.Text(value, props.Text{Top: 2, Left: 2})
+			})
+		})
+	}
+	document.SetBorder(false)
+
+	writeTotals(document, [][]string{
+		{"Contract Total", schedule.Total.String()},
+		{"Received to date", schedule.Received.String()},
+		{"Balance Due", schedule.Outstanding.String()},
+	})
+
+	writeDocumentNote(layout, fmt.Sprintf("Thank you! This receipt confirms %s has received the payment above towards the agreement for %s.", branding.IssuedBy(record.Studio).Name, record.Contract.EventDetails.EventName))
+
+	output, err := document.Output()
+	if err != nil {
+		return nil, fmt.Errorf("could not render receipt with error : %w", err)
+	}
+
+	return output.Bytes(), nil
+}
+
+// writeDocumentHeader prints the document title and numbers on the right, below the studio details at the top of
+// the page.
+func writeDocumentHeader(document pdf.Maroto, title string, details [][]string) {
+	document.Row(12, func() {
+		document.Col(12, func() {
+			document.Text(title, props.Text{Top: 2, Style: consts.Bold, Size: 16, Align: consts.Right, Color: branding.Current().Accent()})
+		})
+	})
+
+	for _, detail := range details {
+		label, value := detail[0], detail[1]
+		document.Row(5, func() {
+			document.Col(12, func() {
+				document.Text(fmt.Sprintf("%s: %s", label, value), props.Text{Size: 9, Align: consts.Right})
+			})
+		})
+	}
+
+	document.Row(8, func() { document.Text("") })
+}
+
+// writeParties prints the client the document is for and the event it covers.
+func writeParties(document pdf.Maroto, record *contract.Record, clientLabel string) {
+	client := record.Contract.ClientDetails
+	event := record.Contract.EventDetails
+
+	document.Row(24, func() {
+		document.Col(6, func() {
+			document.Text(clientLabel, props.Text{Style: consts.Bold})
+			document.Text(client.ClientName, props.Text{Top: 5})
+			document.Text(client.ClientEmail, props.Text{Top: 10})
+		})
+		document.Col(6, func() {
+			document.Text("Event", props.Text{Style: consts.Bold, Align: consts.Right})
+			document.Text(event.EventName, props.Text{Top: 5, Align: consts.Right})
+			document.Text(fmt.Sprintf("%s, %s", event.EventDate, event.EventCoverageTime.Format(event.EventDate)), props.Text{Top: 10, Align: consts.Right})
+			document.Text(event.EventVenue, props.Text{Top: 15, Align: consts.Right})
+		})
+	})
+}
+
+// writeTotals prints label and amount pairs aligned to the right, the last one in bold.
+func writeTotals(document pdf.Maroto, totals [][]string) {
+	document.Row(4, func() { document.Text("") })
+
+	for i, total := range totals {
+		label, value := total[0], total[1]
+		style := consts.Normal
+		if i == len(totals)-1 {
+			style = consts.Bold
+		}
+
+		document.Row(6, func() {
+			document.ColSpace(6)
+			document.Col(4, func() {
+				document.Text(label, props.Text{Top: 1, Style: style, Align: consts.Right})
+			})
+			document.Col(2, func() {
+				document.Text(value, props.Text{Top: 1, Right: 2, Style: style, Align: consts.Right})
+			})
+		})
+	}
+}
+
+// writeDocumentNote prints a note in small print below the totals.
+func writeDocumentNote(layout *textLayout, note string) {
+	layout.writeParagraph(Paragraph{
+		Runs:   []Run{{Text: note}},
+		Size:   8,
+		Top:    8,
+		Bottom: 4,
+	})
+}
@@ -0,0 +1,260 @@
+package pdfcreator
+
+import (
+	"strings"
+
+	"github.com/johnfercher/maroto/pkg/consts"
+	"github.com/johnfercher/maroto/pkg/pdf"
+	"github.com/johnfercher/maroto/pkg/props"
+	"github.com/jung-kurt/gofpdf"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/branding"
+)
+
+// pointsPerMM converts font sizes to the millimetres pages are laid out in.
+const pointsPerMM = 72 / 25.4
+
+// defaultFontSize is the size text is printed at unless it says otherwise, as in maroto.
+const defaultFontSize = 10
+
+// Run is a piece of a paragraph in one style. The runs of a paragraph flow on from each other, so a bold heading can
+// run into the regular text after it.
+type Run struct {
+	Text  string
+	Style consts.Style
+}
+
+// Paragraph is text wrapped across the width of the page, or of the columns left of it by the marker.
+type Paragraph struct {
+	Runs []Run
+	// Size is the font size in points, defaultFontSize when zero.
+	Size float64
+	// Marker is printed ahead of the first line, as with clause numbers and footnote asterisks. It is centred in
+	// MarkerCols grid columns of its own when set, and printed in the indent of the text otherwise.
+	Marker     string
+	MarkerCols uint
+	// Indent is how far the text is from the left of its column, in millimetres.
+	Indent float64
+	// Top and Bottom are the space above the first line and below the last one, in millimetres.
+	Top    float64
+	Bottom float64
+}
+
+// textLayout wraps text with the metrics of the fonts it is printed in, so rows are as tall as their text and
+// paragraphs carry on over page breaks.
+type textLayout struct {
+	document pdf.Maroto
+	// metrics is a document of its own, only used to measure text with.
+	metrics *gofpdf.Fpdf
+	// translate encodes text for the core fonts, as maroto does before printing it.
+	translate func(string) string
+	family    string
+}
+
+// textSpan is text in one style printed x millimetres from the left of its line.
+type textSpan struct {
+	text  string
+	style consts.Style
+	x     float64
+}
+
+type textLine []textSpan
+
+// newTextLayout measures text in the fonts of the brand, which maroto prints as they are where Arial is encoded first.
+func newTextLayout(document pdf.Maroto, brand branding.Branding) *textLayout {
+	metrics := gofpdf.New("P", "mm", "Letter", branding.FontLocation)
+	files := brand.Fonts.Files()
+	if len(files) == 0 {
+		return &textLayout{
+			document:  document,
+			metrics:   metrics,
+			translate: metrics.UnicodeTranslatorFromDescriptor(""),
+			family:    consts.Arial,
+		}
+	}
+
+	for style, file := range files {
+		metrics.AddUTF8Font(branding.FontFamily, string(style), file)
+	}
+	return &textLayout{
+		document:  document,
+		metrics:   metrics,
+		translate: func(text string) string { return text },
+		family:    branding.FontFamily,
+	}
+}
+
+// writeParagraph prints the paragraph, moving the lines that do not fit on the current page to the next one. A
+// paragraph is not split to leave a single line of it on either page.
+func (layout *textLayout) writeParagraph(paragraph Paragraph) {
+	size := paragraph.size()
+	lineHeight := size / pointsPerMM
+	textCols := uint(consts.MaxGridSum) - paragraph.MarkerCols
+	lines := layout.wrap(paragraph.Runs, size, layout.colWidth(textCols)-paragraph.Indent)
+
+	marker := paragraph.Marker
+	for len(lines) > 0 {
+		// Maroto starts the next page with the first row that does not fit, so a full page is as good as a new one
+		left, page := pageSpace(layout.document)
+		atTop := left >= page
+		if left < 1 {
+			left, atTop = page, true
+		}
+
+		available := left - paragraph.Top
+		fits := int(available / lineHeight)
+		if fits >= len(lines) && float64(len(lines))*lineHeight+paragraph.Bottom > available {
+			fits = len(lines) - 1
+		}
+		if fits < len(lines) && len(lines)-fits < 2 {
+			fits--
+		}
+		if fits < 2 && fits < len(lines) && !atTop {
+			newPage(layout.document)
+			continue
+		}
+		if fits < 1 {
+			fits = 1
+		}
+		if fits > len(lines) {
+			fits = len(lines)
+		}
+
+		chunk, last := lines[:fits], fits == len(lines)
+		height := paragraph.Top + float64(len(chunk))*lineHeight
+		if last {
+			height += paragraph.Bottom
+		}
+
+		chunkMarker := marker
+		layout.document.Row(height, func() {
+			if paragraph.MarkerCols > 0 {
+				layout.document.Col(paragraph.MarkerCols, func() {
+					layout.document.Text(chunkMarker, props.Text{Top: paragraph.Top, Family: layout.family, Size: size, Align: consts.Center})
+				})
+			}
+			layout.document.Col(textCols, func() {
+				if paragraph.MarkerCols == 0 && chunkMarker != "" {
+					layout.document.Text(chunkMarker, props.Text{Top: paragraph.Top, Family: layout.family, Size: size, Extrapolate: true})
+				}
+				layout.writeLines(chunk, size, paragraph.Top, paragraph.Indent)
+			})
+		})
+
+		marker, lines = "", lines[fits:]
+		if !last {
+			newPage(layout.document)
+		}
+	}
+}
+
+// height is how tall the paragraph is when it is not split over pages.
+func (layout *textLayout) height(paragraph Paragraph) float64 {
+	size := paragraph.size()
+	lines := layout.wrap(paragraph.Runs, size, layout.colWidth(uint(consts.MaxGridSum)-paragraph.MarkerCols)-paragraph.Indent)
+	return paragraph.Top + float64(len(lines))*size/pointsPerMM + paragraph.Bottom
+}
+
+// textHeight is how tall text is once maroto wraps it across the given grid columns, less left and right padding.
+func (layout *textLayout) textHeight(text string, style consts.Style, size float64, cols uint, padding float64) float64 {
+	if size == 0 {
+		size = defaultFontSize
+	}
+	// Maroto counts the space after the last word of a line against its width
+	layout.metrics.SetFont(layout.family, string(style), size)
+	width := layout.colWidth(cols) - padding - layout.width(" ")
+
+	lines := layout.wrap([]Run{{Text: text, Style: style}}, size, width)
+	return float64(len(lines)) * size / pointsPerMM
+}
+
+// writeLines prints wrapped lines in the current column, each span on its own so runs can change style mid line.
+func (layout *textLayout) writeLines(lines []textLine, size, top, indent float64) {
+	lineHeight := size / pointsPerMM
+	for i, line := range lines {
+		for _, span := range line {
+			layout.document.Text(span.text, props.Text{
+				Top:         top + float64(i)*lineHeight,
+				Left:        indent + span.x,
+				Family:      layout.family,
+				Style:       span.style,
+				Size:        size,
+				Extrapolate: true,
+			})
+		}
+	}
+}
+
+// wrap breaks the runs into lines no wider than width, breaking between words. Words in the same style are kept in
+// one span, and line breaks in the text are kept.
+func (layout *textLayout) wrap(runs []Run, size, width float64) []textLine {
+	lines := []textLine{nil}
+	x, spaced := 0.0, false
+	for _, run := range runs {
+		layout.metrics.SetFont(layout.family, string(run.Style), size)
+		spaceWidth := layout.width(" ")
+
+		for i, text := range strings.Split(run.Text, "\n") {
+			if i > 0 {
+				lines = append(lines, nil)
+				x, spaced = 0, false
+			}
+			if strings.HasPrefix(text, " ") {
+				spaced = true
+			}
+
+			for _, word := range strings.Fields(text) {
+				line := &lines[len(lines)-1]
+				gap := 0.0
+				if spaced && len(*line) > 0 {
+					gap = spaceWidth
+				}
+
+				wordWidth := layout.width(word)
+				if len(*line) > 0 && x+gap+wordWidth > width {
+					lines = append(lines, nil)
+					line = &lines[len(lines)-1]
+					x, gap = 0, 0
+				}
+
+				if n := len(*line); n > 0 && (*line)[n-1].style == run.Style {
+					if gap > 0 {
+						word = " " + word
+					}
+					(*line)[n-1].text += word
+				} else {
+					*line = append(*line, textSpan{text: word, style: run.Style, x: x + gap})
+				}
+				x += gap + wordWidth
+				spaced = true
+			}
+
+			if text != "" {
+				spaced = strings.HasSuffix(text, " ")
+			}
+		}
+	}
+
+	if len(lines[len(lines)-1]) == 0 && len(lines) > 1 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// width measures text in the current font of the metrics document, the way maroto translates it for print.
+func (layout *textLayout) width(text string) float64 {
+	return layout.metrics.GetStringWidth(layout.translate(text))
+}
+
+// colWidth is the width of a number of grid columns.
+func (layout *textLayout) colWidth(cols uint) float64 {
+	pageWidth, _ := layout.document.GetPageSize()
+	left, _, right, _ := layout.document.GetPageMargins()
+	return (pageWidth - left - right) * float64(cols) / consts.MaxGridSum
+}
+
+func (paragraph Paragraph) size() float64 {
+	if paragraph.Size == 0 {
+		return defaultFontSize
+	}
+	return paragraph.Size
+}
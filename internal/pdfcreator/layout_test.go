@@ -0,0 +1,113 @@
+package pdfcreator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/johnfercher/maroto/pkg/consts"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/branding"
+)
+
+const loremIpsum = "Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore et " +
+	"dolore magna aliqua. Ut enim ad minim veniam, quis nostrud exercitation ullamco laboris nisi ut aliquip ex ea " +
+	"commodo consequat. "
+
+func TestWrap(t *testing.T) {
+	_, layout := newDocument("test", branding.Current())
+	runs := []Run{{Text: "Cancellation:", Style: consts.Bold}, {Text: " " + strings.Repeat(loremIpsum, 2), Style: consts.Normal}}
+
+	lines := layout.wrap(runs, defaultFontSize, 80)
+	if len(lines) < 3 {
+		t.Fatalf("wrapped into %d lines", len(lines))
+	}
+
+	// The bold heading runs into the text after it on the first line
+	first := lines[0]
+	if len(first) != 2 || first[0].text != "Cancellation:" || first[0].style != consts.Bold || first[1].style != consts.Normal || first[1].x <= 0 {
+		t.Errorf("first line = %+v", first)
+	}
+
+	var words []string
+	for i, line := range lines {
+		last := line[len(line)-1]
+		layout.metrics.SetFont(layout.family, string(last.style), defaultFontSize)
+		if width := last.x + layout.width(last.text); width > 80 {
+			t.Errorf("line %d is %.1fmm wide : %+v", i+1, width, line)
+		}
+		for _, span := range line {
+			words = append(words, strings.Fields(span.text)...)
+		}
+	}
+	if want := strings.Fields("Cancellation: " + strings.Repeat(loremIpsum, 2)); strings.Join(words, " ") != strings.Join(want, " ") {
+		t.Errorf("wrapped words = %q", words)
+	}
+
+	// Line breaks in the text are kept, a trailing one does not add an empty line
+	if lines := layout.wrap([]Run{{Text: "one\ntwo\n"}}, defaultFontSize, 80); len(lines) != 2 || lines[1][0].text != "two" {
+		t.Errorf("lines = %+v", lines)
+	}
+}
+
+func TestWriteParagraph(t *testing.T) {
+	document, layout := newDocument("test", branding.Current())
+	_, page := pageSpace(document)
+
+	paragraph := Paragraph{Runs: []Run{{Text: strings.Repeat(loremIpsum, 40)}}, Top: 2, Bottom: 2}
+	height := layout.height(paragraph)
+	if height <= page {
+		t.Fatalf("a %.0fmm paragraph fits on a %.0fmm page", height, page)
+	}
+
+	// The paragraph carries on over the page break
+	layout.writeParagraph(paragraph)
+	// Pages are numbered from one
+	if pages := document.GetCurrentPage(); pages != 2 {
+		t.Errorf("paragraph %.0fmm tall takes %d pages", height, pages)
+	}
+	if left, _ := pageSpace(document); left <= 0 || left >= page {
+		t.Errorf("%.1fmm left on the last page", left)
+	}
+
+	if _, err := document.Output(); err != nil {
+		t.Fatalf("Output : %v", err)
+	}
+}
+
+func TestWriteParagraphKeepsLinesTogether(t *testing.T) {
+	document, layout := newDocument("test", branding.Current())
+	lineHeight := defaultFontSize / pointsPerMM
+
+	// Leave room for one and a half lines, a three line paragraph is moved to the next page rather than leaving one
+	// of its lines behind
+	left, page := pageSpace(document)
+	document.Row(left-1.5*lineHeight, func() {
+		document.ColSpace(uint(consts.MaxGridSum))
+	})
+	paragraph := Paragraph{Runs: []Run{{Text: "one\ntwo\nthree"}}}
+	layout.writeParagraph(paragraph)
+
+	if document.GetCurrentPage() != 2 {
+		t.Fatalf("paragraph is on page %d", document.GetCurrentPage())
+	}
+	if left, _ := pageSpace(document); left < page-3*lineHeight-1 {
+		t.Errorf("%.1fmm left on the next page, the paragraph was split", left)
+	}
+}
+
+func TestRowHeight(t *testing.T) {
+	_, layout := newDocument("test", branding.Current())
+	cols := []uint{2, 10}
+
+	if height := layout.rowHeight(8, cols, []string{"Photos", "Edited photos"}); height != 8 {
+		t.Errorf("row of short cells is %.1fmm", height)
+	}
+
+	// A row is as tall as its longest cell, in the narrowest column it is given
+	long := layout.rowHeight(8, cols, []string{strings.Repeat("photos ", 20), "Edited photos"})
+	if want := 2 + layout.textHeight(strings.Repeat("photos ", 20), consts.Normal, 0, 2, 0); long != want || long <= 8 {
+		t.Errorf("row of a long cell is %.1fmm, want %.1fmm", long, want)
+	}
+	if wide := layout.rowHeight(8, cols, []string{"Photos", strings.Repeat("photos ", 20)}); wide >= long {
+		t.Errorf("the same text is %.1fmm tall in a wide column and %.1fmm in a narrow one", wide, long)
+	}
+}
@@ -3,110 +3,198 @@ package pdfcreator
 import (
 	"fmt"
 	"math"
-	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/johnfercher/maroto/pkg/consts"
 	"github.com/johnfercher/maroto/pkg/pdf"
 	"github.com/johnfercher/maroto/pkg/props"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/branding"
 	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/contract"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/pricing"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/terms"
 )
 
 type Pdf struct {
 	pdf.Maroto
 }
 
-func CreateContractsPage(details *contract.Contract) (*string, error) {
-	pageLen := 150.0 + len(details.DeliverableDetails)*20.0
-	contractsPage := pdf.NewMarotoCustomSize(consts.Portrait, "Letter", "mm", 215.9, float64(pageLen))
+// RenderContract renders the contract in memory. Once the record is signed the signature block carries the
+// client's signature and an audit certificate page is appended.
+func RenderContract(record *contract.Record) ([]byte, error) {
+	document, layout, err := newContractDocument(record)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.Signature != nil {
+		newPage(document)
+		writeAuditCertificate(document, layout, record)
+	}
+
+	output, err := document.Output()
+	if err != nil {
+		return nil, fmt.Errorf("could not render contract with error : %w", err)
+	}
+
+	return output.Bytes(), nil
+}
+
+// newContractDocument lays out the whole agreement: the header, event details, deliverables, payment schedule,
+// terms and the signature block. Rows that do not fit on a page are moved to the next one.
+func newContractDocument(record *contract.Record) (pdf.Maroto, *textLayout, error) {
+	brand := branding.IssuedBy(record.Studio)
+	document, layout := newDocument(record.ID, brand)
+
+	schedule, err := pricing.ScheduleFor(record)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not work out the payment schedule with error : %w", err)
+	}
+
+	writeAgreementHeader(document, layout, brand, record)
+	writeContractDetails(document, layout, brand, &record.Contract, schedule)
+	newPage(document)
+	err = writeTerms(document, layout, brand, record)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = writeSignatureBlock(document, layout, record)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return document, layout, nil
+}
+
+// writeAgreementHeader introduces the parties of the agreement at the top of the first page.
+func writeAgreementHeader(document pdf.Maroto, layout *textLayout, brand branding.Branding, record *contract.Record) {
+	issuedOn := record.CreatedAt
+	if issuedOn.IsZero() {
+		issuedOn = time.Now()
+	}
+
+	document.Row(10, func() {
+		document.Col(12, func() {
+			document.Text(fmt.Sprintf("%s SERVICES AGREEMENT", strings.ToUpper(brand.Name)), props.Text{
+				Top:   1,
+				Style: consts.Bold,
+				Size:  16,
+				Align: consts.Center,
+				Color: brand.Accent(),
+			})
+		})
+	})
 
+	layout.writeParagraph(Paragraph{
+		Runs:   []Run{{Text: fmt.Sprintf("This Agreement was made and entered into on %s between %s, a %s based %s Service and %s(\"Client\"). The Client hereby agree as follows:", issuedOn.Format("01/02/2006"), brand.LegalName, brand.Location, brand.Services, record.Contract.ClientDetails.ClientName)}},
+		Top:    2,
+		Bottom: 5,
+	})
+}
+
+// writeContractDetails adds the event, deliverable and payment details of the contract.
+func writeContractDetails(contractsPage pdf.Maroto, layout *textLayout, brand branding.Branding, details *contract.Contract, schedule *pricing.Schedule) {
 	contractsPage.SetBorder(true)
 
 	contractsPage.Row(6, func() {
 		contractsPage.Col(12, func() {
 			// Note: Left-alignment is the default for text
 			contractsPage.Text("Event Details", props.Text{
-				Top:    1,
-				Family: consts.Arial,
-				Align:  consts.Center,
+				Top:   1,
+				Align: consts.Center,
 			})
 		})
 	})
 
-	contractsPage.Row(20, func() {
-		contractsPage.Col(12, func() {
-			// Note: Left-alignment is the default for text
-			contractsPage.Text(fmt.Sprintf("Event : %s", details.EventDetails.EventName), props.Text{
-				Top:    2,
-				Left:   2,
-				Right:  2,
-				Family: consts.Arial,
-				Align:  consts.Left,
-			})
-			contractsPage.Text(fmt.Sprintf("Event Date: %s", details.EventDetails.EventDate), props.Text{
-				Top:    6,
-				Left:   2,
-				Right:  2,
-				Family: consts.Arial,
-				Align:  consts.Left,
-			})
-			contractsPage.Text(fmt.Sprintf("Event Coverage Time: %s", details.EventDetails.EventCoverageTime), props.Text{
-				Top:    10,
-				Left:   2,
-				Right:  2,
-				Family: consts.Arial,
-				Align:  consts.Left,
-			})
-			contractsPage.Text(fmt.Sprintf("Event Venue: %s", details.EventDetails.EventVenue), props.Text{
-				Top:    14,
-				Left:   2,
-				Right:  2,
-				Family: consts.Arial,
-				Align:  consts.Left,
+	if len(details.EventDetails.Sessions) > 0 {
+		writeSessions(contractsPage, details.EventDetails)
+	} else {
+		contractsPage.Row(20, func() {
+			contractsPage.Col(12, func() {
+				// Note: Left-alignment is the default for text
+				contractsPage.Text(fmt.Sprintf("Event : %s", details.EventDetails.EventName), props.Text{
+					Top:   2,
+					Left:  2,
+					Right: 2,
+					Align: consts.Left,
+				})
+				contractsPage.Text(fmt.Sprintf("Event Date: %s", details.EventDetails.EventDate), props.Text{
+					Top:   6,
+					Left:  2,
+					Right: 2,
+					Align: consts.Left,
+				})
+				contractsPage.Text(fmt.Sprintf("Event Coverage Time: %s", details.EventDetails.EventCoverageTime.Format(details.EventDetails.EventDate)), props.Text{
+					Top:   10,
+					Left:  2,
+					Right: 2,
+					Align: consts.Left,
+				})
+				contractsPage.Text(fmt.Sprintf("Event Venue: %s", details.EventDetails.EventVenue), props.Text{
+					Top:   14,
+					Left:  2,
+					Right: 2,
+					Align: consts.Left,
+				})
 			})
 		})
-	})
+	}
 
 	contractsPage.Row(10, func() { contractsPage.Text("") })
 
 	contractsPage.Row(6, func() {
 		contractsPage.Col(12, func() {
 			contractsPage.Text("Deliverables", props.Text{
-				Top:    1,
-				Family: consts.Arial,
-				Align:  consts.Center,
+				Top:   1,
+				Align: consts.Center,
 			})
 		})
 	})
 
-	contractsPage.Row(6, func() {
-		contractsPage.Col(1, func() {
-			contractsPage.Text("S.No", props.Text{Top: 1, Align: consts.Center})
-		})
-		contractsPage.Col(3, func() {
-			contractsPage.Text("Deliverable", props.Text{Top: 1, Align: consts.Center})
-		})
-		contractsPage.Col(2, func() {
-			contractsPage.Text("Quantity", props.Text{Top: 1, Align: consts.Center})
-		})
-		contractsPage.Col(3, func() {
-			contractsPage.Text("Mode", props.Text{Top: 1, Align: consts.Center})
-		})
-		contractsPage.Col(3, func() {
-			contractsPage.Text("Delivered on or before**", props.Text{Top: 1, Align: consts.Center})
+	deliverablesHeader := func() {
+		contractsPage.Row(6, func() {
+			contractsPage.Col(1, func() {
+				contractsPage.Text("S.No", props.Text{Top: 1, Align: consts.Center})
+			})
+			contractsPage.Col(3, func() {
+				contractsPage.Text("Deliverable", props.Text{Top: 1, Align: consts.Center})
+			})
+			contractsPage.Col(2, func() {
+				contractsPage.Text("Quantity", props.Text{Top: 1, Align: consts.Center})
+			})
+			contractsPage.Col(3, func() {
+				contractsPage.Text("Mode", props.Text{Top: 1, Align: consts.Center})
+			})
+			contractsPage.Col(3, func() {
+				contractsPage.Text("Delivered on or before**", props.Text{Top: 1, Align: consts.Center})
+			})
 		})
-	})
+	}
+	deliverablesHeader()
 
 	for i, deliverable := range details.DeliverableDetails {
-		contractsPage.Row(15, func() {
+		desc := deliverable.Description
+		if deliverable.Session != "" {
+			desc = fmt.Sprintf("%s - %s", desc, deliverable.Session)
+		}
+		if i == 0 {
+			desc = fmt.Sprintf("%s*", desc)
+		}
+
+		height := layout.rowHeight(15, []uint{1, 3, 2, 3, 3}, []string{"", desc, deliverable.Quantity, deliverable.Mode, deliverable.DeliveryDate.String()})
+
+		// Deliverables continuing on a new page get the column headers again
+		if keepTogether(contractsPage, height) {
+			deliverablesHeader()
+		}
+
+		contractsPage.Row(height, func() {
 			contractsPage.Col(1, func() {
 				contractsPage.Text(fmt.Sprintf("%d.", i+1), props.Text{Top: 1, Align: consts.Center})
 			})
 			contractsPage.Col(3, func() {
-				desc := deliverable.Description
-				if i == 0 {
-					desc = fmt.Sprintf("%s*", desc)
-				}
 				contractsPage.Text(desc, props.Text{Top: 1, Align: consts.Center})
 			})
 			contractsPage.Col(2, func() {
@@ -116,344 +204,335 @@ func CreateContractsPage(details *contract.Contract) (*string, error) {
 				contractsPage.Text(deliverable.Mode, props.Text{Top: 1, Align: consts.Center})
 			})
 			contractsPage.Col(3, func() {
-				contractsPage.Text(deliverable.DeliveryDate, props.Text{Top: 1, Align: consts.Center})
+				contractsPage.Text(deliverable.DeliveryDate.String(), props.Text{Top: 1, Align: consts.Center})
 			})
 		})
 	}
 
 	contractsPage.SetBorder(false)
 
-	Astrisks := `Red Dot Studios does not provide RAW images/ video files unless specifically mentioned above in the section 2. Acquiring RAW images/ video comes at an additional cost.`
-
-	contractsPage.Row(7, func() {
-		contractsPage.Col(12, func() {
-			contractsPage.Text("*", props.Text{
-				Top:    1,
-				Family: consts.Arial,
-				Size:   8,
-			})
-			contractsPage.Text(Astrisks, props.Text{
-				Family: consts.Arial,
-				Top:    1,
-				Left:   4,
-				Size:   8,
-			})
-		})
-	})
+	studio := brand.Name
 
-	Astrisks = `Red Dot Studios timelines for the delivery of projects depends on various factors which include the scale of the event, type of the service and number of deliverables, editing work according to the clients needs etc. We strive to deliver the first digital copy for the events by the date mentioned above. That being said, the delivery for event projects could take up to 2 months and wedding projects could take up to 6 months in special cases.`
+	Astrisks := fmt.Sprintf(`%s does not provide RAW images/ video files unless specifically mentioned above in the section 2. Acquiring RAW images/ video comes at an additional cost.`, studio)
+	writeFootnote(layout, "*", Astrisks)
 
-	contractsPage.Row(8, func() {
-		contractsPage.Col(12, func() {
-			contractsPage.Text("**", props.Text{
-				Family: consts.Arial,
-				Size:   8,
-			})
-			contractsPage.Text(Astrisks, props.Text{
-				Family: consts.Arial,
-				Left:   4,
-				Size:   8,
-			})
-		})
-	})
+	Astrisks = fmt.Sprintf(`%s timelines for the delivery of projects depends on various factors which include the scale of the event, type of the service and number of deliverables, editing work according to the clients needs etc. We strive to deliver the first digital copy for the events by the date mentioned above. That being said, the delivery for event projects could take up to 2 months and wedding projects could take up to 6 months in special cases.`, studio)
+	writeFootnote(layout, "**", Astrisks)
 
 	contractsPage.Row(10, func() { contractsPage.Text("") })
 
 	contractsPage.SetBorder(true)
 
+	charges, payments := chargeRows(schedule.Charges), paymentRows(schedule)
+	height := 6.0
+	for _, rows := range []struct {
+		cols []uint
+		rows [][]string
+	}{{chargeColumns, charges}, {paymentColumns, payments}} {
+		for _, row := range rows.rows {
+			height += layout.rowHeight(6, rows.cols, row)
+		}
+	}
+
+	// The payment table is short enough to always be kept on one page
+	keepTogether(contractsPage, height)
+
 	contractsPage.Row(6, func() {
 		contractsPage.Col(12, func() {
 			contractsPage.Text("Payment Details", props.Text{
-				Top:    1,
-				Family: consts.Arial,
-				Align:  consts.Center,
+				Top:   1,
+				Align: consts.Center,
 			})
 		})
 	})
 
-	contractsPage.Row(6, func() {
-		contractsPage.Col(1, func() {
-			contractsPage.Text("S.No", props.Text{Top: 1, Align: consts.Center})
-		})
-		contractsPage.Col(4, func() {
-			contractsPage.Text("Description", props.Text{Top: 1, Align: consts.Center})
-		})
-		contractsPage.Col(2, func() {
-			contractsPage.Text("Amount", props.Text{Top: 1, Align: consts.Center})
-		})
-		contractsPage.Col(3, func() {
-			contractsPage.Text("Mode", props.Text{Top: 1, Align: consts.Center})
-		})
-		contractsPage.Col(2, func() {
-			contractsPage.Text("Status", props.Text{Top: 1, Align: consts.Center})
+	for _, row := range charges {
+		writeTableRow(contractsPage, layout.rowHeight(6, chargeColumns, row), chargeColumns, row)
+	}
+	for _, row := range payments {
+		writeTableRow(contractsPage, layout.rowHeight(6, paymentColumns, row), paymentColumns, row)
+	}
+
+	contractsPage.SetBorder(false)
+
+	if bookingFee, ok := schedule.Payment(pricing.PaymentBookingFee); ok {
+		Astrisks = fmt.Sprintf(`%s has to be paid during the time of signing this contract. We do not guarantee the availability of our team for the event date until this payment is made in full.`, paymentNote("Booking fee", bookingFee))
+		writeFootnote(layout, "*", Astrisks)
+	}
+
+	if _, ok := schedule.Payment(pricing.PaymentInstallment); ok {
+		Astrisks = `Installments have to be paid on or before their due dates. We do not guarantee the availability of our team for the event date until the first installment is paid in full. Editing work only begins on the receipt of complete payment.`
+		writeFootnote(layout, "*", Astrisks)
+	}
+
+	if balance, ok := schedule.Payment(pricing.PaymentBalance); ok {
+		Astrisks = fmt.Sprintf(`%s has to be paid on the day of the event in cash. We do not accept any other mode of payment except cash; there is no exception to this policy. Editing work only begins on the receipt of complete payment.`, paymentNote("Remaining Project Payment", balance))
+		writeFootnote(layout, "**", Astrisks)
+	}
+}
+
+// writeSessions lists the sessions of an event split over several days or venues, each on a row of its own.
+func writeSessions(contractsPage pdf.Maroto, event contract.EventDetails) {
+	contractsPage.Row(8, func() {
+		contractsPage.Col(12, func() {
+			contractsPage.Text(fmt.Sprintf("Event : %s", event.EventName), props.Text{
+				Top:   2,
+				Left:  2,
+				Right: 2,
+				Align: consts.Left,
+			})
 		})
 	})
 
-	sNo := 0
-	if details.PaymentDetails.AdvancePaid != 0 {
-		sNo++
+	sessionsHeader := func() {
 		contractsPage.Row(6, func() {
 			contractsPage.Col(1, func() {
-				contractsPage.Text(fmt.Sprintf("%d.", sNo), props.Text{Top: 1, Align: consts.Center})
+				contractsPage.Text("S.No", props.Text{Top: 1, Align: consts.Center})
 			})
-			contractsPage.Col(4, func() {
-				contractsPage.Text("Advance Paid", props.Text{Top: 1, Align: consts.Center})
+			contractsPage.Col(2, func() {
+				contractsPage.Text("Session", props.Text{Top: 1, Align: consts.Center})
 			})
 			contractsPage.Col(2, func() {
-				contractsPage.Text(fmt.Sprintf("$%d", details.PaymentDetails.AdvancePaid), props.Text{Top: 1, Align: consts.Center})
+				contractsPage.Text("Date", props.Text{Top: 1, Align: consts.Center})
 			})
 			contractsPage.Col(3, func() {
-				contractsPage.Text(details.PaymentDetails.AdvancePaymentMode, props.Text{Top: 1, Align: consts.Center})
+				contractsPage.Text("Coverage Time", props.Text{Top: 1, Align: consts.Center})
 			})
-			contractsPage.Col(2, func() {
-				contractsPage.Text("Paid", props.Text{Top: 1, Align: consts.Center})
+			contractsPage.Col(4, func() {
+				contractsPage.Text("Venue", props.Text{Top: 1, Align: consts.Center})
 			})
 		})
 	}
+	sessionsHeader()
 
-	bookingFeeToBePaid := int64(math.Round(float64(details.PaymentDetails.TotalAmount) * 0.25))
+	for i, session := range event.Sessions {
+		// Sessions continuing on a new page get the column headers again
+		if keepTogether(contractsPage, 10) {
+			sessionsHeader()
+		}
 
-	if bookingFeeToBePaid%10 != 0 {
-		bookingFeeToBePaid = (((bookingFeeToBePaid / 10) + 1) * 10) - details.PaymentDetails.AdvancePaid
-	} else {
-		bookingFeeToBePaid = bookingFeeToBePaid - details.PaymentDetails.AdvancePaid
+		contractsPage.Row(10, func() {
+			contractsPage.Col(1, func() {
+				contractsPage.Text(fmt.Sprintf("%d.", i+1), props.Text{Top: 1, Align: consts.Center})
+			})
+			contractsPage.Col(2, func() {
+				contractsPage.Text(session.Name, props.Text{Top: 1, Align: consts.Center})
+			})
+			contractsPage.Col(2, func() {
+				contractsPage.Text(session.Date.String(), props.Text{Top: 1, Align: consts.Center})
+			})
+			contractsPage.Col(3, func() {
+				contractsPage.Text(session.CoverageTime.Format(session.Date), props.Text{Top: 1, Align: consts.Center})
+			})
+			contractsPage.Col(4, func() {
+				contractsPage.Text(session.Venue, props.Text{Top: 1, Align: consts.Center})
+			})
+		})
 	}
+}
 
-	remainingProjectPayment := details.PaymentDetails.TotalAmount - bookingFeeToBePaid - details.PaymentDetails.AdvancePaid
-	sNo++
-	contractsPage.Row(10, func() {
-		contractsPage.Col(1, func() {
-			contractsPage.Text(fmt.Sprintf("%d.", sNo), props.Text{Top: 1, Align: consts.Center})
-		})
-		contractsPage.Col(4, func() {
-			contractsPage.Text("Booking fee* - 25% (Non-refundable)", props.Text{Top: 2, Align: consts.Center})
-		})
-		contractsPage.Col(2, func() {
-			contractsPage.Text(fmt.Sprintf("$%d", bookingFeeToBePaid), props.Text{Top: 2, Align: consts.Center})
-		})
-		contractsPage.Col(3, func() {
-			//contractsPage.Text("Venmo - @reddot_studios", props.Text{Top: 0.5, Align: consts.Center})
-			contractsPage.Text("Zelle - 774-448-8352", props.Text{Top: 4, Align: consts.Center})
-		})
-		contractsPage.Col(2, func() {
-			contractsPage.Text("To be paid", props.Text{Top: 2, Align: consts.Center})
-		})
-	})
+var (
+	// chargeColumns are the grid columns of the number, description, tax and amount of the charges.
+	chargeColumns = []uint{1, 6, 3, 2}
+	// paymentColumns are the grid columns of the number, description, amount, mode and status of the payments.
+	paymentColumns = []uint{1, 4, 2, 3, 2}
+)
 
-	sNo++
-	contractsPage.Row(6, func() {
-		contractsPage.Col(1, func() {
-			contractsPage.Text(fmt.Sprintf("%d.", sNo), props.Text{Top: 1, Align: consts.Center})
-		})
-		contractsPage.Col(4, func() {
-			contractsPage.Text("Remaining Project Payment** - 75%", props.Text{Top: 1, Align: consts.Center})
-		})
-		contractsPage.Col(2, func() {
-			contractsPage.Text(fmt.Sprintf("$%d", remainingProjectPayment), props.Text{Top: 1, Align: consts.Center})
-		})
-		contractsPage.Col(3, func() {
-			contractsPage.Text("Cash Only", props.Text{Top: 1, Align: consts.Center})
-		})
-		contractsPage.Col(2, func() {
-			contractsPage.Text("To be paid", props.Text{Top: 1, Align: consts.Center})
-		})
-	})
+// chargeRows break the grand total down into the services, discount, line items, subtotal, fees and tax ahead of the
+// payments it is split into. Contracts without either only have the services, so there is nothing to break down.
+func chargeRows(charges *pricing.Charges) [][]string {
+	if !charges.Itemized() {
+		return nil
+	}
 
-	contractsPage.Row(6, func() {
-		contractsPage.Col(1, func() {
-			contractsPage.Text("", props.Text{Top: 1, Align: consts.Center})
-		})
-		contractsPage.Col(4, func() {
-			contractsPage.Text("Total - 100%", props.Text{Top: 1, Align: consts.Center})
-		})
-		contractsPage.Col(2, func() {
-			contractsPage.Text(fmt.Sprintf("$%d", details.PaymentDetails.TotalAmount), props.Text{Top: 1, Align: consts.Center})
-		})
-		contractsPage.Col(3, func() {
-			contractsPage.Text("", props.Text{Top: 1, Align: consts.Center})
-		})
-		contractsPage.Col(2, func() {
-			contractsPage.Text("", props.Text{Top: 1, Align: consts.Center})
-		})
-	})
+	rows := [][]string{{"S.No", "Charges", "Tax", "Amount"}}
+	for i, item := range charges.Items {
+		tax := "Non-taxable"
+		if item.Taxable {
+			tax = "Taxable"
+		}
+		rows = append(rows, []string{fmt.Sprintf("%d.", i+1), item.Description, tax, item.Amount.String()})
 
-	contractsPage.SetBorder(false)
+		// The discount is taken off the services, so clients see what they saved right below the original price
+		if i == 0 && !charges.Discount.IsZero() {
+			rows = append(rows, []string{"", charges.DiscountLabel, "", "-" + charges.Discount.String()})
+		}
+	}
+	rows = append(rows, []string{"", "Subtotal", "", charges.Subtotal.String()})
+	if !charges.Fees.IsZero() {
+		rows = append(rows, []string{"", "Fees (included in subtotal)", "", charges.Fees.String()})
+	}
+	for _, tax := range charges.Taxes {
+		rows = append(rows, []string{"", tax.Name, fmt.Sprintf("%s%% of %s", strconv.FormatFloat(tax.Percent, 'f', -1, 64), tax.Taxable), tax.Amount.String()})
+	}
+	return append(rows, []string{"", "Grand Total", "", charges.GrandTotal.String()})
+}
 
-	Astrisks = `Booking fee(25%) has to be paid during the time of signing this contract. We do not guarantee the availability of our team for the event date until this payment is made in full.`
+// paymentRows are the payments the total is split into under their column headers, with the total at the end.
+func paymentRows(schedule *pricing.Schedule) [][]string {
+	rows := [][]string{{"S.No", "Description", "Amount", "Mode", "Status"}}
+	for i, payment := range schedule.Payments {
+		description, status := payment.Description, paymentStatus(payment, "To be paid")
+		switch payment.Kind {
+		case pricing.PaymentBookingFee:
+			description = paymentLabel(description+"*", payment) + " (Non-refundable)"
+		case pricing.PaymentBalance:
+			description = paymentLabel(description+"**", payment)
+		case pricing.PaymentInstallment:
+			if i == 0 {
+				description += "*"
+			}
+			description = paymentLabel(description, payment)
+			status = paymentStatus(payment, "Due "+payment.DueDate)
+		}
+		rows = append(rows, []string{fmt.Sprintf("%d.", i+1), description, payment.Amount.String(), payment.Mode, status})
+	}
+	return append(rows, []string{"", "Total - 100%", schedule.Total.String(), "", ""})
+}
 
-	contractsPage.Row(7, func() {
-		contractsPage.Col(12, func() {
-			contractsPage.Text("*", props.Text{
-				Top:    1,
-				Family: consts.Arial,
-				Size:   8,
-			})
-			contractsPage.Text(Astrisks, props.Text{
-				Family: consts.Arial,
-				Top:    1,
-				Left:   4,
-				Size:   8,
+// writeTableRow prints a row of centred cells across the given grid columns.
+func writeTableRow(document pdf.Maroto, height float64, cols []uint, cells []string) {
+	document.Row(height, func() {
+		for i, cell := range cells {
+			document.Col(cols[i], func() {
+				document.Text(cell, props.Text{Top: 1, Align: consts.Center})
 			})
-		})
+		}
 	})
+}
 
-	Astrisks = `Remaining Project Payment(75%) has to be paid on the day of the event in cash. We do not accept any other mode of payment except cash; there is no exception to this policy. Editing work only begins on the receipt of complete payment.`
+// paymentStatus is what the Status column shows for a payment, going by the payments received so far.
+func paymentStatus(payment pricing.Payment, unpaid string) string {
+	switch {
+	case payment.Paid:
+		return "Paid"
+	case payment.Received.Amount > 0:
+		return "Part paid"
+	default:
+		return unpaid
+	}
+}
 
-	contractsPage.Row(8, func() {
-		contractsPage.Col(12, func() {
-			contractsPage.Text("**", props.Text{
-				Family: consts.Arial,
-				Size:   8,
-			})
-			contractsPage.Text(Astrisks, props.Text{
-				Family: consts.Arial,
-				Left:   4,
-				Size:   8,
-			})
-		})
+// paymentLabel appends the payment's share of the total to its label, as in "Booking fee - 25%".
+func paymentLabel(label string, payment pricing.Payment) string {
+	if percent := payment.PercentLabel(); percent != "" {
+		return fmt.Sprintf("%s - %s", label, percent)
+	}
+	return label
+}
+
+// writeFootnote prints a note on the table above it in small print, its marker ahead of it.
+func writeFootnote(layout *textLayout, marker, note string) {
+	layout.writeParagraph(Paragraph{
+		Runs:   []Run{{Text: note}},
+		Size:   8,
+		Marker: marker,
+		Indent: 4,
+		Top:    1,
+		Bottom: 1,
 	})
+}
 
-	subDir := "contracts"
-	_, err := os.Stat(subDir)
-	if os.IsNotExist(err) {
-		err := os.Mkdir(subDir, 0755)
-		if err != nil {
-			return nil, fmt.Errorf("could not create contracts directory with error : %w", err)
-		}
+// paymentNote names a payment in the footnotes, as in "Booking fee(25%)".
+func paymentNote(label string, payment pricing.Payment) string {
+	if percent := payment.PercentLabel(); percent != "" {
+		return fmt.Sprintf("%s(%s)", label, percent)
 	}
+	return label
+}
 
-	fileName := fmt.Sprintf("contracts/contract-specifics-%s-%s", strings.ReplaceAll(details.EventDetails.EventName, " ", "_"), strings.ReplaceAll(details.ClientDetails.ClientName, " ", "_"))
-	err = contractsPage.OutputFileAndClose(fileName + ".pdf")
+// writeTerms adds the clauses of the terms the contract was issued under that apply to its type of event, with the
+// clauses negotiated for the contract, numbered in order.
+func writeTerms(termsPage pdf.Maroto, layout *textLayout, brand branding.Branding, record *contract.Record) error {
+	template, err := terms.Lookup(record.TermsVersion)
 	if err != nil {
-		return nil, fmt.Errorf("could not save pdf file with error : %w", err)
+		return fmt.Errorf("could not find the terms of the contract with error : %w", err)
+	}
+	clauses, err := template.Render(&record.Contract, brand.Studio)
+	if err != nil {
+		return fmt.Errorf("could not fill in the terms of the contract with error : %w", err)
 	}
 
-	return &fileName, nil
-}
-
-func CreateTermsPage(details *contract.Contract) (*string, error) {
-	termsPage := pdf.NewMarotoCustomSize(consts.Portrait, "Letter", "mm", 215.9, 138.0)
-	termsPage.SetPageMargins(2, 3, 5)
-
-	termsPage.Row(28, func() {
-		termsPage.Col(1, func() {
-			termsPage.Text("1.", props.Text{Top: 1, Align: consts.Center})
-		})
-		termsPage.Col(11, func() {
-			termsPage.Text("Reschedule Policy:", props.Text{Top: 1, Family: consts.Arial,
-				Style: consts.Bold})
-			termsPage.Text("We understand that event dates and times can change due to several factors. We ", props.Text{Top: 1, Left: 34, Family: consts.Arial})
-			termsPage.Text(fmt.Sprintf("accommodate up to 1 hour of delay/ prepone in the event time on the day of the event if informed 4 hours prior to the start of the event time. The Red Dot Studio team will also try to accommodate requests to extend stay to cover the event if the event runs longer than anticipated. That being this request will come at an extra hourly prorated cost of $%d/hr which is non-negotiable and is subject to availability. Our schedules are packed during busy months and the team might have to cover an event before or after the client’s event. We always encourage our clients to book our time conservatively if they anticipate any delays. Red Dot Studios allows one reschedule of the event if informed 24 hrs prior to the date of the event provided project payment is made in full while requesting the reschedule. There are no exceptions to this clause.", details.PaymentDetails.PerHourExtra), props.Text{Top: 4.5, Family: consts.Arial})
+	termsPage.Row(10, func() {
+		termsPage.Col(12, func() {
+			termsPage.Text(template.Title, props.Text{
+				Top:   1,
+				Style: consts.Bold,
+				Size:  12,
+				Align: consts.Center,
+				Color: brand.Accent(),
+			})
 		})
 	})
 
-	termsPage.Row(18, func() {
-		termsPage.Col(1, func() {
-			termsPage.Text("2.", props.Text{Top: 1, Align: consts.Center})
+	for i, clause := range clauses {
+		// The heading runs into the clause, so it reads as the start of it
+		layout.writeParagraph(Paragraph{
+			Runs: []Run{
+				{Text: strings.TrimSuffix(clause.Heading, ":") + ": ", Style: consts.Bold},
+				{Text: clause.Body},
+			},
+			Marker:     fmt.Sprintf("%d.", i+1),
+			MarkerCols: 1,
+			Top:        1,
+			Bottom:     2.5,
 		})
-		termsPage.Col(11, func() {
-			termsPage.Text("Cancellation/ Termination:", props.Text{Top: 1, Family: consts.Arial,
-				Style: consts.Bold})
-			termsPage.Text("Client may decide to terminate this agreement at any time upon a written", props.Text{Top: 1, Left: 46, Family: consts.Arial})
-			termsPage.Text("notification(Email, whatsapp, instagram) to Red Dot Studios. After a written notification, this agreement would be deemed void. Red Dot Studios shall be entitled to retain the booking advance made by the client. Red Dot Studios is entitled to take other bookings for the event date after the termination of the contract and any further requests will only be subject to availability and would require drafting a new contract.", props.Text{Top: 4.5, Family: consts.Arial})
-		})
-	})
+	}
 
-	termsPage.Row(14, func() {
-		termsPage.Col(1, func() {
-			termsPage.Text("3.", props.Text{Top: 1, Align: consts.Center})
-		})
-		termsPage.Col(11, func() {
-			termsPage.Text("Modifications to video deliverables:", props.Text{Top: 1, Family: consts.Arial,
-				Style: consts.Bold})
-			termsPage.Text("Client agrees to our creative choices and artistic/style decisions that", props.Text{Top: 1, Left: 61.5, Family: consts.Arial})
-			termsPage.Text("we make during editing. Once we deliver the first digital copy we allow the client to request up-to two revisions both of which need to be requested within one week of the delivered digital copy. Final soft copy for the project will be delivered to the client after the second revision, and the project will be termed Completed.", props.Text{Top: 4.5, Family: consts.Arial})
-		})
-	})
+	return nil
+}
 
-	termsPage.Row(7, func() {
-		termsPage.Col(1, func() {
-			termsPage.Text("4.", props.Text{Top: 1, Align: consts.Center})
-		})
-		termsPage.Col(11, func() {
-			termsPage.Text("Data Retention Policy:", props.Text{Top: 1, Family: consts.Arial,
-				Style: consts.Bold})
-			termsPage.Text("We erase all the client data after the completion of the project and do not take any", props.Text{Top: 1, Left: 39, Family: consts.Arial})
-			termsPage.Text("additional requests for changes. ", props.Text{Top: 4.5, Family: consts.Arial})
-		})
-	})
+// rowHeight is how tall a table row has to be for the longest of its cells to fit, and at least minimum.
+func (layout *textLayout) rowHeight(minimum float64, cols []uint, cells []string) float64 {
+	height := minimum
+	for i, cell := range cells {
+		height = math.Max(height, 2+layout.textHeight(cell, consts.Normal, 0, cols[i], 0))
+	}
+	return height
+}
 
-	termsPage.Row(17.5, func() {
-		termsPage.Col(1, func() {
-			termsPage.Text("5.", props.Text{Top: 1, Align: consts.Center})
-		})
-		termsPage.Col(11, func() {
-			termsPage.Text("Copyright:", props.Text{Top: 1, Family: consts.Arial,
-				Style: consts.Bold})
-			termsPage.Text("Red Dot Studios shall retain the copyright to all the photographs and/or videography shot during", props.Text{Top: 1, Left: 19, Family: consts.Arial})
-			termsPage.Text("the event. The Client shall not remove or alter any watermarks, logos, or other identification marks included on the photographs and/or videography without the prior written consent. Red Dot Studios also holds the rights to use the edited videos and photos for the purpose of promoting our business in digital media, including but not limited to our website and social media.", props.Text{Top: 4.5, Family: consts.Arial})
-		})
-	})
+// keepTogether moves to a new page when the next height worth of rows would not fit on the current one and reports
+// whether it did.
+func keepTogether(document pdf.Maroto, height float64) bool {
+	left, _ := pageSpace(document)
+	if height <= left {
+		return false
+	}
 
-	termsPage.Row(7.5, func() {
-		termsPage.Col(1, func() {
-			termsPage.Text("6.", props.Text{Top: 1, Align: consts.Center})
-		})
-		termsPage.Col(11, func() {
-			termsPage.Text("Additional Services:", props.Text{Top: 1, Family: consts.Arial,
-				Style: consts.Bold})
-			termsPage.Text("The Client may request additional services from the Red Dot Studios, but such", props.Text{Top: 1, Left: 35, Family: consts.Arial})
-			termsPage.Text("requests must be made before the event date and such requests will only be entertained subject to availability.", props.Text{Top: 4.5, Family: consts.Arial})
-		})
-	})
+	newPage(document)
+	return true
+}
 
-	termsPage.Row(7.5, func() {
-		termsPage.Col(1, func() {
-			termsPage.Text("7.", props.Text{Top: 1, Align: consts.Center})
-		})
-		termsPage.Col(11, func() {
-			termsPage.Text("Limitation of Liability:", props.Text{Top: 1, Family: consts.Arial,
-				Style: consts.Bold})
-			termsPage.Text("The Client may request additional services from the Red Dot Studios, but such", props.Text{Top: 1, Left: 38, Family: consts.Arial})
-			termsPage.Text("requests must be made before the event date and such requests will only be entertained subject to availability.", props.Text{Top: 4.5, Family: consts.Arial})
-		})
-	})
+// newPage fills the rest of the current page with a borderless row and starts a new page. Maroto's own AddPage
+// rounds the offset down, which leaves gofpdf to break the page halfway through the filler row once rows are not
+// whole millimetres tall. The page is broken by a borderless row too, as maroto pads the footer and header with rows
+// of its own that would otherwise be drawn with the borders of the table the page breaks in.
+func newPage(document pdf.Maroto) {
+	border := document.GetBorder()
+	document.SetBorder(false)
+	defer document.SetBorder(border)
 
-	termsPage.Row(7.5, func() {
-		termsPage.Col(1, func() {
-			termsPage.Text("8.", props.Text{Top: 1, Align: consts.Center})
+	if left, _ := pageSpace(document); left > 0 {
+		document.Row(left, func() {
+			document.ColSpace(uint(consts.MaxGridSum))
 		})
-		termsPage.Col(11, func() {
-			termsPage.Text("Entire Agreement:", props.Text{Top: 1, Family: consts.Arial,
-				Style: consts.Bold})
-			termsPage.Text("This Agreement constitutes the entire agreement between the parties and supersedes", props.Text{Top: 1, Left: 32, Family: consts.Arial})
-			termsPage.Text("all prior negotiations, representations, understandings, and agreements between the parties.", props.Text{Top: 4.5, Family: consts.Arial})
-		})
-	})
-
-	subDir := "contracts"
-	_, err := os.Stat(subDir)
-	if os.IsNotExist(err) {
-		err := os.Mkdir(subDir, 0755)
-		if err != nil {
-			return nil, fmt.Errorf("could not create contracts directory with error : %w", err)
-		}
 	}
-
-	fileNameT := fmt.Sprintf("contracts/contract-terms-%s-%s", strings.ReplaceAll(details.EventDetails.EventName, " ", "_"), strings.ReplaceAll(details.ClientDetails.ClientName, " ", "_"))
-	// pdfName := fmt.Sprintf("contacts/contract-%s-%s.pdf", strings.ReplaceAll("House Warming", " ", "_"), strings.ReplaceAll("Sainath", " ", "_"))
-	err = termsPage.OutputFileAndClose(fileNameT + ".pdf")
-	if err != nil {
-		return nil, fmt.Errorf("could not save pdf file with error : %w", err)
-	}
-
-	return &fileNameT, nil
+	document.Row(1, func() {
+		document.ColSpace(uint(consts.MaxGridSum))
+	})
 }
 
-func CleanUpPdfs() error {
-	err := os.RemoveAll("contracts")
-	if err != nil {
-		return fmt.Errorf("failure while cleaning up pdfs from contracts directory with err : %w", err)
+// pageSpace is the height left on the current page and the height of a whole page, within the margins and between
+// the page header and footer. The header is only printed with the first row of a page, so a document without any
+// rows yet has a whole page left.
+func pageSpace(document pdf.Maroto) (left float64, page float64) {
+	_, pageHeight := document.GetPageSize()
+	_, top, _, bottom := document.GetPageMargins()
+	page = math.Floor(pageHeight-top-bottom) - headerHeight - footerHeight
+
+	offset := document.GetCurrentOffset()
+	if offset == 0 {
+		return page, page
 	}
-	return nil
+	return page + headerHeight - offset, page
 }
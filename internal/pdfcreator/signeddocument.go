@@ -0,0 +1,139 @@
+package pdfcreator
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/johnfercher/maroto/pkg/consts"
+	"github.com/johnfercher/maroto/pkg/pdf"
+	"github.com/johnfercher/maroto/pkg/props"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/branding"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/contract"
+)
+
+// auditTimeLayout is how timestamps are printed on the audit certificate.
+const auditTimeLayout = "Jan 2, 2006 15:04:05 MST"
+
+func writeSignatureBlock(document pdf.Maroto, layout *textLayout, record *contract.Record) error {
+	document.Row(15, func() { document.Text("") })
+
+	witness := Paragraph{
+		Runs:   []Run{{Text: "IN WITNESS WHEREOF, the Client has signed this Agreement electronically."}},
+		Top:    1,
+		Bottom: 3.5,
+	}
+	// The signature is kept on the same page as what it signs for
+	keepTogether(document, layout.height(witness)+25+12)
+	layout.writeParagraph(witness)
+
+	signature := record.Signature
+	var err error
+	document.Row(25, func() {
+		document.Col(6, func() {
+			if signature == nil {
+				return
+			}
+
+			if signature.Drawing != "" {
+				err = document.Base64Image(signature.Drawing, consts.Png, props.Rect{Percent: 90, Center: true})
+				return
+			}
+
+			document.Text(signature.Name, props.Text{
+				Top:    12,
+				Family: consts.Courier,
+				Style:  consts.BoldItalic,
+				Size:   16,
+				Align:  consts.Center,
+			})
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("could not add the drawn signature with error : %w", err)
+	}
+
+	document.Row(12, func() {
+		document.Col(6, func() {
+			document.Signature(fmt.Sprintf("Client: %s", record.Contract.ClientDetails.ClientName), props.Font{Size: 9})
+		})
+		document.Col(6, func() {
+			signedOn := ""
+			if signature != nil {
+				signedOn = signature.SignedAt.UTC().Format("01/02/2006")
+			}
+			document.Text(signedOn, props.Text{Top: 0, Align: consts.Center})
+			document.Signature("Date", props.Font{Size: 9})
+		})
+	})
+
+	return nil
+}
+
+func writeAuditCertificate(document pdf.Maroto, layout *textLayout, record *contract.Record) {
+	signature := record.Signature
+	brand := branding.IssuedBy(record.Studio)
+
+	document.Row(10, func() {
+		document.Col(12, func() {
+			document.Text("Signature Audit Certificate", props.Text{
+				Top:   2,
+				Style: consts.Bold,
+				Size:  14,
+				Align: consts.Center,
+				Color: brand.Accent(),
+			})
+		})
+	})
+
+	method := "Typed name"
+	if signature.Drawing != "" {
+		method = "Drawn signature"
+	}
+
+	nameCheck := "Matches the client name on the contract"
+	if signature.NeedsReview {
+		nameCheck = "Does not match the client name on the contract, flagged for review"
+	}
+
+	rows := [][]string{
+		{"Contract ID", record.ID},
+		{"Client", fmt.Sprintf("%s <%s>", record.Contract.ClientDetails.ClientName, record.Contract.ClientDetails.ClientEmail)},
+		{"Signed name", signature.Name},
+		{"Name check", nameCheck},
+		{"Signature method", method},
+		{"Signed through", strings.ReplaceAll(string(signature.Source), "_", " ")},
+		{"Signed at", signature.SignedAt.UTC().Format(auditTimeLayout)},
+		{"IP address", signature.IPAddress},
+		{"User agent", signature.UserAgent},
+		{"Document fingerprint (SHA-256)", signature.DocumentHash},
+	}
+
+	document.SetBorder(true)
+	for _, row := range rows {
+		label, value := row[0], row[1]
+		if value == "" {
+			value = "-"
+		}
+
+		// Long values such as user agents wrap onto as many lines as they need
+		height := math.Max(10, 3+layout.textHeight(value, consts.Normal, 8, 8, 4))
+		keepTogether(document, height)
+
+		document.Row(height, func() {
+			document.Col(4, func() {
+				document.Text(label, props.Text{Top: 1.5, Left: 2, Style: consts.Bold})
+			})
+			document.Col(8, func() {
+				document.Text(value, props.Text{Top: 1.5, Left: 2, Right: 2, Size: 8})
+			})
+		})
+	}
+	document.SetBorder(false)
+
+	layout.writeParagraph(Paragraph{
+		Runs: []Run{{Text: fmt.Sprintf("This certificate was generated by %s when the Client signed the Agreement above. The document fingerprint is the SHA-256 of the contract PDF the Client was shown at the time of signing, which is kept as it was issued.", brand.Name)}},
+		Size: 8,
+		Top:  4,
+	})
+}
@@ -0,0 +1,186 @@
+package pricing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"sync"
+
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/money"
+)
+
+// DefaultPolicy is the payment policy of contracts that do not name one.
+const DefaultPolicy = "standard"
+
+var (
+	ErrUnknownPolicy = errors.New("unknown payment policy")
+	ErrInvalidPolicy = errors.New("invalid payment policy")
+)
+
+// PolicyType is how a payment policy works out the booking fee.
+type PolicyType string
+
+const (
+	// PolicyPercentage charges a share of the total as the booking fee.
+	PolicyPercentage PolicyType = "percentage"
+	// PolicyFlat charges a fixed retainer as the booking fee.
+	PolicyFlat PolicyType = "flat"
+	// PolicyTiered picks a percentage or a fixed retainer depending on the total.
+	PolicyTiered PolicyType = "tiered"
+)
+
+// Policy decides how much of a contract is due as a booking fee when signing, the rest is due on the event day.
+type Policy struct {
+	Type PolicyType `json:"type"`
+	// Percent of the total due as the booking fee, for percentage policies.
+	Percent float64 `json:"percent,omitempty"`
+	// Amount due as the booking fee in whole units of the contract's currency, for flat policies.
+	Amount int64 `json:"amount,omitempty"`
+	// RoundUpTo rounds percentage booking fees up to a multiple of this many whole units.
+	RoundUpTo int64 `json:"roundUpTo,omitempty"`
+	// Tiers are checked in order of UpTo, for tiered policies.
+	Tiers []Tier `json:"tiers,omitempty"`
+}
+
+// Tier is the booking fee of a tiered policy for totals up to UpTo.
+type Tier struct {
+	// UpTo is the largest total the tier applies to, zero for no limit.
+	UpTo      int64   `json:"upTo,omitempty"`
+	Percent   float64 `json:"percent,omitempty"`
+	Amount    int64   `json:"amount,omitempty"`
+	RoundUpTo int64   `json:"roundUpTo,omitempty"`
+}
+
+var (
+	policiesLock sync.RWMutex
+	// policies are the payment policies contracts can name, keyed by name.
+	policies = map[string]Policy{
+		DefaultPolicy:  {Type: PolicyPercentage, Percent: 25, RoundUpTo: 10},
+		"deposit-30":   {Type: PolicyPercentage, Percent: 30, RoundUpTo: 10},
+		"deposit-50":   {Type: PolicyPercentage, Percent: 50, RoundUpTo: 10},
+		"no-deposit":   {Type: PolicyPercentage, Percent: 0},
+		"wedding-flat": {Type: PolicyFlat, Amount: 500},
+		"tiered": {Type: PolicyTiered, Tiers: []Tier{
+			{UpTo: 1000, Percent: 50, RoundUpTo: 10},
+			{UpTo: 5000, Percent: 30, RoundUpTo: 10},
+			{Percent: 25, RoundUpTo: 10},
+		}},
+	}
+)
+
+// LoadPolicies adds the payment policies in a JSON file keyed by name, replacing built in policies of the same name.
+func LoadPolicies(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed while reading payment policies file with err : %w", err)
+	}
+
+	var loaded map[string]Policy
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return fmt.Errorf("failed while parsing payment policies file with err : %w", err)
+	}
+
+	for name, policy := range loaded {
+		if err := policy.validate(); err != nil {
+			return fmt.Errorf("payment policy %s : %w", name, err)
+		}
+		sort.SliceStable(policy.Tiers, func(i, j int) bool {
+			upTo, next := policy.Tiers[i].UpTo, policy.Tiers[j].UpTo
+			return upTo != 0 && (next == 0 || upTo < next)
+		})
+		loaded[name] = policy
+	}
+
+	policiesLock.Lock()
+	defer policiesLock.Unlock()
+	for name, policy := range loaded {
+		policies[name] = policy
+	}
+
+	return nil
+}
+
+// LookupPolicy returns the named payment policy, the default policy when name is empty.
+func LookupPolicy(name string) (Policy, error) {
+	if name == "" {
+		name = DefaultPolicy
+	}
+
+	policiesLock.RLock()
+	defer policiesLock.RUnlock()
+
+	policy, ok := policies[name]
+	if !ok {
+		return Policy{}, fmt.Errorf("%w : %s", ErrUnknownPolicy, name)
+	}
+	return policy, nil
+}
+
+func (policy Policy) validate() error {
+	switch policy.Type {
+	case PolicyPercentage:
+		return validateFee(policy.Percent, 0, policy.RoundUpTo)
+	case PolicyFlat:
+		return validateFee(0, policy.Amount, 0)
+	case PolicyTiered:
+		if len(policy.Tiers) == 0 {
+			return fmt.Errorf("%w : tiered policies need at least one tier", ErrInvalidPolicy)
+		}
+		for _, tier := range policy.Tiers {
+			if tier.UpTo < 0 {
+				return fmt.Errorf("%w : tier limits cannot be negative", ErrInvalidPolicy)
+			}
+			if err := validateFee(tier.Percent, tier.Amount, tier.RoundUpTo); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w : unknown policy type %q", ErrInvalidPolicy, policy.Type)
+	}
+}
+
+func validateFee(percent float64, amount int64, roundUpTo int64) error {
+	if percent < 0 || percent > 100 {
+		return fmt.Errorf("%w : percent should be between 0 and 100", ErrInvalidPolicy)
+	}
+	if amount < 0 {
+		return fmt.Errorf("%w : amount cannot be negative", ErrInvalidPolicy)
+	}
+	if percent != 0 && amount != 0 {
+		return fmt.Errorf("%w : a booking fee is either a percent or an amount", ErrInvalidPolicy)
+	}
+	if roundUpTo < 0 {
+		return fmt.Errorf("%w : roundUpTo cannot be negative", ErrInvalidPolicy)
+	}
+	return nil
+}
+
+// bookingFee is the booking fee the policy charges on the total, and its share of the total in percent when the
+// policy is percentage based.
+func (policy Policy) bookingFee(total money.Money) (money.Money, float64) {
+	switch policy.Type {
+	case PolicyFlat:
+		return money.FromMajor(policy.Amount, total.Currency), 0
+	case PolicyTiered:
+		tier := policy.Tiers[len(policy.Tiers)-1]
+		for _, candidate := range policy.Tiers {
+			if candidate.UpTo == 0 || total.Cmp(money.FromMajor(candidate.UpTo, total.Currency)) <= 0 {
+				tier = candidate
+				break
+			}
+		}
+		if tier.Amount != 0 {
+			return money.FromMajor(tier.Amount, total.Currency), 0
+		}
+		return percentOf(total, tier.Percent, tier.RoundUpTo), tier.Percent
+	default:
+		return percentOf(total, policy.Percent, policy.RoundUpTo), policy.Percent
+	}
+}
+
+func percentOf(total money.Money, percent float64, roundUpTo int64) money.Money {
+	return total.Share(money.BasisPoints(percent)).RoundUpTo(money.FromMajor(roundUpTo, total.Currency))
+}
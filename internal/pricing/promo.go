@@ -0,0 +1,146 @@
+package pricing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/contract"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/money"
+)
+
+var (
+	ErrUnknownPromoCode = errors.New("unknown promo code")
+	ErrExpiredPromoCode = errors.New("promo code has expired")
+	ErrInvalidPromoCode = errors.New("invalid promo code")
+	ErrInvalidDiscount  = errors.New("invalid discount")
+)
+
+// PromoCode is a discount clients can be given by code, such as a seasonal promotion or a referral.
+type PromoCode struct {
+	Percent float64 `json:"percent,omitempty"`
+	// Amount taken off in whole units of the contract's currency, for fixed discounts.
+	Amount int64  `json:"amount,omitempty"`
+	Reason string `json:"reason"`
+	// Expires is the last day the code can be used on, in the event date layout. Codes without one do not expire.
+	Expires string `json:"expires,omitempty"`
+	// MaxUses is how many contracts the code can be used on, zero for no limit.
+	MaxUses int64 `json:"maxUses,omitempty"`
+}
+
+var (
+	promoCodesLock sync.RWMutex
+	// promoCodes are the promo codes contracts can use, keyed by code in upper case.
+	promoCodes = map[string]PromoCode{}
+)
+
+// LoadPromoCodes adds the promo codes in a JSON file keyed by code, replacing loaded codes of the same name.
+func LoadPromoCodes(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed while reading promo codes file with err : %w", err)
+	}
+
+	var loaded map[string]PromoCode
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return fmt.Errorf("failed while parsing promo codes file with err : %w", err)
+	}
+
+	for code, promo := range loaded {
+		if err := promo.validate(); err != nil {
+			return fmt.Errorf("promo code %s : %w", code, err)
+		}
+	}
+
+	promoCodesLock.Lock()
+	defer promoCodesLock.Unlock()
+	for code, promo := range loaded {
+		promoCodes[strings.ToUpper(code)] = promo
+	}
+
+	return nil
+}
+
+// ResetPromoCodes removes every loaded promo code, so tests that load their own do not leave them to others.
+func ResetPromoCodes() {
+	promoCodesLock.Lock()
+	defer promoCodesLock.Unlock()
+	promoCodes = map[string]PromoCode{}
+}
+
+// LookupPromoCode returns the promo code if it can still be used on the given day. Codes are not case sensitive.
+func LookupPromoCode(code string, on time.Time) (PromoCode, error) {
+	promoCodesLock.RLock()
+	promo, ok := promoCodes[strings.ToUpper(code)]
+	promoCodesLock.RUnlock()
+
+	if !ok {
+		return PromoCode{}, fmt.Errorf("%w : %s", ErrUnknownPromoCode, code)
+	}
+	if promo.Expires != "" {
+		// Expiry dates are validated when loading, so they compare in order as strings
+		if on.Format(contract.EventDateLayout) > promo.Expires {
+			return PromoCode{}, fmt.Errorf("%w : %s expired on %s", ErrExpiredPromoCode, code, promo.Expires)
+		}
+	}
+	return promo, nil
+}
+
+// Discount is the discount the promo code gives on a contract in the given currency.
+func (promo PromoCode) Discount(code string, currency string) *contract.Discount {
+	discount := &contract.Discount{
+		Code:    strings.ToUpper(code),
+		Percent: promo.Percent,
+		Amount:  money.New(0, currency),
+		Reason:  promo.Reason,
+	}
+	if promo.Amount != 0 {
+		discount.Amount = money.FromMajor(promo.Amount, currency)
+	}
+	return discount
+}
+
+func (promo PromoCode) validate() error {
+	if promo.Reason == "" {
+		return fmt.Errorf("%w : a reason is required", ErrInvalidPromoCode)
+	}
+	if promo.Expires != "" {
+		if _, err := time.Parse(contract.EventDateLayout, promo.Expires); err != nil {
+			return fmt.Errorf("%w : expires should be a date like 2006-01-02", ErrInvalidPromoCode)
+		}
+	}
+	if promo.MaxUses < 0 {
+		return fmt.Errorf("%w : maxUses cannot be negative", ErrInvalidPromoCode)
+	}
+	if (promo.Percent == 0) == (promo.Amount == 0) {
+		return fmt.Errorf("%w : a promo code gives either a percent or an amount off", ErrInvalidPromoCode)
+	}
+	if promo.Percent < 0 || promo.Percent > 100 || promo.Amount < 0 {
+		return fmt.Errorf("%w : the discount should be between nothing and the whole total", ErrInvalidPromoCode)
+	}
+	return nil
+}
+
+// discountOf works out how much the discount takes off the total.
+func discountOf(discount *contract.Discount, total money.Money) (money.Money, error) {
+	switch {
+	case discount.Percent < 0 || discount.Percent > 100:
+		return money.Money{}, fmt.Errorf("%w : percent should be between 0 and 100", ErrInvalidDiscount)
+	case discount.Amount.IsNegative():
+		return money.Money{}, fmt.Errorf("%w : amount cannot be negative", ErrInvalidDiscount)
+	case discount.Percent != 0 && !discount.Amount.IsZero():
+		return money.Money{}, fmt.Errorf("%w : a discount is either a percent or an amount", ErrInvalidDiscount)
+	case discount.Percent != 0:
+		return total.Share(money.BasisPoints(discount.Percent)), nil
+	case discount.Amount.IsZero():
+		return money.Money{}, fmt.Errorf("%w : a percent or an amount is required", ErrInvalidDiscount)
+	case discount.Amount.Cmp(total) > 0:
+		return money.Money{}, fmt.Errorf("%w : %s is more than the total amount of %s", ErrInvalidDiscount, discount.Amount, total)
+	default:
+		return discount.Amount, nil
+	}
+}
@@ -0,0 +1,82 @@
+package pricing
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/money"
+)
+
+func loadTestPromoCodes(t *testing.T, content string) error {
+	t.Helper()
+	t.Cleanup(ResetPromoCodes)
+
+	path := filepath.Join(t.TempDir(), "promo.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return LoadPromoCodes(path)
+}
+
+func TestLookupPromoCode(t *testing.T) {
+	err := loadTestPromoCodes(t, `{
+		"Summer": {"percent": 10, "reason": "Summer promotion", "expires": "2030-08-31", "maxUses": 20},
+		"FRIEND": {"amount": 100, "reason": "Referral"}
+	}`)
+	if err != nil {
+		t.Fatalf("LoadPromoCodes : %v", err)
+	}
+
+	lastDay := time.Date(2030, 8, 31, 23, 0, 0, 0, time.UTC)
+	promo, err := LookupPromoCode("summer", lastDay)
+	if err != nil {
+		t.Fatalf("LookupPromoCode : %v", err)
+	}
+	if promo.Percent != 10 || promo.MaxUses != 20 {
+		t.Errorf("promo = %+v", promo)
+	}
+
+	discount := promo.Discount("summer", "EUR")
+	if discount.Code != "SUMMER" || discount.Percent != 10 || discount.Amount != money.New(0, "EUR") || discount.Reason != "Summer promotion" {
+		t.Errorf("discount = %+v", discount)
+	}
+
+	if _, err := LookupPromoCode("SUMMER", lastDay.AddDate(0, 0, 1)); !errors.Is(err, ErrExpiredPromoCode) {
+		t.Errorf("lookup after the last day returned %v, want ErrExpiredPromoCode", err)
+	}
+	if _, err := LookupPromoCode("WINTER", lastDay); !errors.Is(err, ErrUnknownPromoCode) {
+		t.Errorf("lookup of an unknown code returned %v, want ErrUnknownPromoCode", err)
+	}
+
+	// Codes without an expiry date can always be used, fixed amounts are in the contract's currency
+	friend, err := LookupPromoCode("friend", time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("LookupPromoCode : %v", err)
+	}
+	if discount := friend.Discount("FRIEND", "CAD"); discount.Amount != money.FromMajor(100, "CAD") || discount.Percent != 0 {
+		t.Errorf("discount = %+v", discount)
+	}
+}
+
+func TestLoadPromoCodesInvalid(t *testing.T) {
+	tests := map[string]string{
+		"no reason":          `{"A": {"percent": 10}}`,
+		"bad expiry":         `{"A": {"percent": 10, "reason": "A", "expires": "31/08/2030"}}`,
+		"negative uses":      `{"A": {"percent": 10, "reason": "A", "maxUses": -1}}`,
+		"percent and amount": `{"A": {"percent": 10, "amount": 10, "reason": "A"}}`,
+		"nothing off":        `{"A": {"reason": "A"}}`,
+		"above 100%":         `{"A": {"percent": 110, "reason": "A"}}`,
+	}
+
+	for name, content := range tests {
+		if err := loadTestPromoCodes(t, content); !errors.Is(err, ErrInvalidPromoCode) {
+			t.Errorf("%s : LoadPromoCodes returned %v, want ErrInvalidPromoCode", name, err)
+		}
+	}
+	if _, err := LookupPromoCode("A", time.Now()); !errors.Is(err, ErrUnknownPromoCode) {
+		t.Errorf("an invalid code was loaded, lookup returned %v", err)
+	}
+}
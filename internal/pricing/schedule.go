@@ -0,0 +1,296 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/branding"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/contract"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/money"
+)
+
+// BalanceMode is how the remaining project payment is paid.
+const BalanceMode = "Cash Only"
+
+// BookingFeeMode is how the booking fee is paid, to the Zelle of the studio.
+func BookingFeeMode() string {
+	return fmt.Sprintf("Zelle - %s", branding.Current().Zelle)
+}
+
+// InstallmentsPolicy is the policy name of schedules made from a contract's installments.
+const InstallmentsPolicy = "installments"
+
+// PaymentKind is what a payment in a schedule is for.
+type PaymentKind string
+
+const (
+	PaymentAdvance    PaymentKind = "advance"
+	PaymentBookingFee PaymentKind = "booking_fee"
+	PaymentBalance    PaymentKind = "balance"
+	// PaymentInstallment is one of the installments of a contract billed in installments.
+	PaymentInstallment PaymentKind = "installment"
+	// PaymentOvertime is coverage of a session beyond its coverage time.
+	PaymentOvertime PaymentKind = "overtime"
+)
+
+// Payment is one line of a payment schedule.
+type Payment struct {
+	Kind        PaymentKind `json:"kind"`
+	Description string      `json:"description"`
+	Amount      money.Money `json:"amount"`
+	// Percent is the share of the total the payment stands for, zero when the policy charges a fixed amount.
+	Percent float64 `json:"percent,omitempty"`
+	Mode    string  `json:"mode"`
+	Due     string  `json:"due"`
+	DueDate string  `json:"dueDate,omitempty"`
+	// Received is how much of the payment has been received so far.
+	Received money.Money `json:"received"`
+	Paid     bool        `json:"paid"`
+}
+
+// Schedule is what a client pays for a contract and when.
+type Schedule struct {
+	Policy string `json:"policy"`
+	// Charges break Total down into the services, line items and tax.
+	Charges     *Charges    `json:"charges"`
+	Total       money.Money `json:"total"`
+	Received    money.Money `json:"received"`
+	Outstanding money.Money `json:"outstanding"`
+	Payments    []Payment   `json:"payments"`
+}
+
+// ScheduleFor works out the payment schedule of a contract with its overtime added and the payments in its ledger
+// applied.
+func ScheduleFor(record *contract.Record) (*Schedule, error) {
+	schedule, err := NewSchedule(record.Contract.PaymentDetails)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule.AddOvertime(record.Overtime)
+	schedule.ApplyLedger(record.Ledger)
+	return schedule, nil
+}
+
+// NewSchedule works out the payment schedule of a contract's grand total from its installments, or from its
+// payment policy when it has none. Any advance already paid counts towards the booking fee.
+func NewSchedule(details contract.PaymentDetails) (*Schedule, error) {
+	charges, err := NewCharges(details)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(details.Installments) > 0 {
+		return newInstallmentSchedule(details, charges)
+	}
+
+	name := details.PaymentPolicy
+	if name == "" {
+		name = DefaultPolicy
+	}
+
+	policy, err := LookupPolicy(name)
+	if err != nil {
+		return nil, err
+	}
+
+	total := charges.GrandTotal
+	if details.AdvancePaid.IsNegative() {
+		return nil, errors.New("advance paid cannot be negative")
+	}
+	if details.AdvancePaid.Cmp(total) > 0 {
+		return nil, errors.New("advance paid cannot be more than the grand total")
+	}
+
+	schedule := &Schedule{Policy: name, Charges: charges, Total: total}
+
+	if !details.AdvancePaid.IsZero() {
+		schedule.Payments = append(schedule.Payments, Payment{
+			Kind:        PaymentAdvance,
+			Description: "Advance Paid",
+			Amount:      details.AdvancePaid,
+			Mode:        details.AdvancePaymentMode,
+			Due:         "already paid",
+			Received:    details.AdvancePaid,
+			Paid:        true,
+		})
+	}
+
+	bookingFee, percent := policy.bookingFee(total)
+	bookingFee = bookingFee.Min(total)
+
+	bookingFeeToBePaid := bookingFee.Sub(details.AdvancePaid)
+	if bookingFeeToBePaid.IsNegative() {
+		bookingFeeToBePaid = money.New(0, details.Currency)
+	}
+	if !bookingFeeToBePaid.IsZero() {
+		schedule.Payments = append(schedule.Payments, Payment{
+			Kind:        PaymentBookingFee,
+			Description: "Booking fee",
+			Amount:      bookingFeeToBePaid,
+			Percent:     percent,
+			Mode:        BookingFeeMode(),
+			Due:         "at signing",
+		})
+	}
+
+	balance := total.Sub(details.AdvancePaid).Sub(bookingFeeToBePaid)
+	if !balance.IsZero() {
+		balancePercent := 0.0
+		if percent != 0 || bookingFee.IsZero() {
+			balancePercent = 100 - percent
+		}
+		schedule.Payments = append(schedule.Payments, Payment{
+			Kind:        PaymentBalance,
+			Description: "Remaining Project Payment",
+			Amount:      balance,
+			Percent:     balancePercent,
+			Mode:        BalanceMode,
+			Due:         "on the day of the event",
+		})
+	}
+
+	schedule.tally()
+	return schedule, nil
+}
+
+func newInstallmentSchedule(details contract.PaymentDetails, charges *Charges) (*Schedule, error) {
+	amounts, err := details.InstallmentAmounts(charges.GrandTotal)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := &Schedule{Policy: InstallmentsPolicy, Charges: charges, Total: charges.GrandTotal}
+	for i, installment := range details.Installments {
+		payment := Payment{
+			Kind:        PaymentInstallment,
+			Description: installment.Description,
+			Amount:      amounts[i],
+			Percent:     installment.Percent,
+			Mode:        strings.Join(installment.Modes, " / "),
+			Due:         "by " + installment.DueDate,
+			DueDate:     installment.DueDate,
+			Paid:        installment.Status == contract.InstallmentPaid,
+		}
+		if payment.Paid {
+			payment.Due = "already paid"
+			payment.Received = payment.Amount
+		}
+		schedule.Payments = append(schedule.Payments, payment)
+	}
+
+	schedule.tally()
+	return schedule, nil
+}
+
+// AddOvertime adds the overtime of the contract's sessions to the schedule, due in cash like the balance.
+func (schedule *Schedule) AddOvertime(overtime []contract.Overtime) {
+	for _, entry := range overtime {
+		schedule.Payments = append(schedule.Payments, Payment{
+			Kind:        PaymentOvertime,
+			Description: fmt.Sprintf("Overtime - %s (%sh)", entry.Session, strconv.FormatFloat(entry.Hours, 'f', -1, 64)),
+			Amount:      entry.Amount,
+			Mode:        BalanceMode,
+			Due:         "at the end of the session",
+		})
+		schedule.Total = schedule.Total.Add(entry.Amount)
+	}
+
+	schedule.tally()
+}
+
+// ApplyLedger allocates the payments received to the scheduled payments in order, marking each one paid once it
+// is covered in full. Anything received beyond the schedule is counted towards the total.
+func (schedule *Schedule) ApplyLedger(ledger []contract.LedgerEntry) {
+	received := money.New(0, schedule.Total.Currency)
+	for _, entry := range ledger {
+		received = received.Add(entry.Amount)
+	}
+
+	for i := range schedule.Payments {
+		payment := &schedule.Payments[i]
+		if payment.Paid {
+			continue
+		}
+
+		allocated := payment.Amount.Sub(payment.Received).Min(received)
+		payment.Received = payment.Received.Add(allocated)
+		payment.Paid = payment.Received.Cmp(payment.Amount) >= 0
+		received = received.Sub(allocated)
+	}
+
+	schedule.tally()
+	schedule.Received = schedule.Received.Add(received)
+	schedule.Outstanding = schedule.Outstanding.Sub(received)
+}
+
+func (schedule *Schedule) tally() {
+	schedule.Received = money.New(0, schedule.Total.Currency)
+	for _, payment := range schedule.Payments {
+		schedule.Received = schedule.Received.Add(payment.Received)
+	}
+	schedule.Outstanding = schedule.Total.Sub(schedule.Received)
+}
+
+// Payment returns the payment of the given kind, if the schedule has one.
+func (schedule *Schedule) Payment(kind PaymentKind) (Payment, bool) {
+	for _, payment := range schedule.Payments {
+		if payment.Kind == kind {
+			return payment, true
+		}
+	}
+	return Payment{}, false
+}
+
+// PercentLabel formats the payment's share of the total, empty when the payment is a fixed amount.
+func (payment Payment) PercentLabel() string {
+	if payment.Percent == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(payment.Percent, 'f', -1, 64) + "%"
+}
+
+// Invoice is the schedule as it stands, to be kept as the invoice issued at issuedAt.
+func (schedule *Schedule) Invoice(issuedAt time.Time) *contract.Invoice {
+	invoice := &contract.Invoice{
+		IssuedAt:   issuedAt,
+		Lines:      make([]contract.InvoiceLine, 0, len(schedule.Payments)),
+		Total:      schedule.Total,
+		Received:   schedule.Received,
+		BalanceDue: schedule.Outstanding,
+	}
+
+	for _, payment := range schedule.Payments {
+		description := payment.Description
+		if percent := payment.PercentLabel(); percent != "" {
+			description = fmt.Sprintf("%s - %s", description, percent)
+		}
+		invoice.Lines = append(invoice.Lines, contract.InvoiceLine{
+			Description: description,
+			Due:         payment.Due,
+			Amount:      payment.Amount,
+			Received:    payment.Received,
+		})
+	}
+
+	return invoice
+}
+
+// Summary describes the schedule in a sentence, for places the payment table is not shown.
+func (schedule *Schedule) Summary() string {
+	summary := fmt.Sprintf("Total: %s.", schedule.Total)
+	if schedule.Charges != nil && !schedule.Charges.Tax.IsZero() {
+		summary = fmt.Sprintf("Total: %s, including %s tax.", schedule.Total, schedule.Charges.Tax)
+	}
+	for _, payment := range schedule.Payments {
+		summary += fmt.Sprintf(" %s: %s %s", payment.Description, payment.Amount, payment.Due)
+		if payment.Mode != "" && !payment.Paid {
+			summary += fmt.Sprintf(" (%s)", payment.Mode)
+		}
+		summary += "."
+	}
+	return summary
+}
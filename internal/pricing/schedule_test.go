@@ -0,0 +1,237 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/contract"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/money"
+)
+
+func dollars(amount float64) money.Money {
+	return money.New(int64(amount*100+0.5), money.DefaultCurrency)
+}
+
+func paymentDetails(total float64) contract.PaymentDetails {
+	return contract.PaymentDetails{
+		Currency:     money.DefaultCurrency,
+		TotalAmount:  dollars(total),
+		PerHourExtra: dollars(150),
+	}
+}
+
+// scheduled is a payment of a schedule as the tests compare it.
+type scheduled struct {
+	kind    PaymentKind
+	amount  money.Money
+	percent float64
+}
+
+func checkPayments(t *testing.T, name string, schedule *Schedule, want []scheduled) {
+	t.Helper()
+
+	if len(schedule.Payments) != len(want) {
+		t.Errorf("%s : %d payments, want %d : %+v", name, len(schedule.Payments), len(want), schedule.Payments)
+		return
+	}
+	for i, payment := range schedule.Payments {
+		if payment.Kind != want[i].kind || payment.Amount != want[i].amount || payment.Percent != want[i].percent {
+			t.Errorf("%s : payment %d is %s of %s at %v%%, want %s of %s at %v%%", name, i+1, payment.Kind, payment.Amount, payment.Percent, want[i].kind, want[i].amount, want[i].percent)
+		}
+	}
+}
+
+func TestNewSchedule(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   float64
+		advance float64
+		policy  string
+		want    []scheduled
+	}{
+		{"standard rounds the booking fee up to ten", 1500, 0, "", []scheduled{
+			{PaymentBookingFee, dollars(380), 25},
+			{PaymentBalance, dollars(1120), 75},
+		}},
+		{"advance counts towards the booking fee", 1500, 100, "", []scheduled{
+			{PaymentAdvance, dollars(100), 0},
+			{PaymentBookingFee, dollars(280), 25},
+			{PaymentBalance, dollars(1120), 75},
+		}},
+		{"advance covering the booking fee", 1500, 400, "", []scheduled{
+			{PaymentAdvance, dollars(400), 0},
+			{PaymentBalance, dollars(1100), 75},
+		}},
+		{"flat retainer", 2000, 0, "wedding-flat", []scheduled{
+			{PaymentBookingFee, dollars(500), 0},
+			{PaymentBalance, dollars(1500), 0},
+		}},
+		{"flat retainer above the total", 300, 0, "wedding-flat", []scheduled{
+			{PaymentBookingFee, dollars(300), 0},
+		}},
+		{"no deposit", 1500, 0, "no-deposit", []scheduled{
+			{PaymentBalance, dollars(1500), 100},
+		}},
+		{"lowest tier", 800, 0, "tiered", []scheduled{
+			{PaymentBookingFee, dollars(400), 50},
+			{PaymentBalance, dollars(400), 50},
+		}},
+		{"middle tier", 3000, 0, "tiered", []scheduled{
+			{PaymentBookingFee, dollars(900), 30},
+			{PaymentBalance, dollars(2100), 70},
+		}},
+		{"top tier", 6001, 0, "tiered", []scheduled{
+			{PaymentBookingFee, dollars(1510), 25},
+			{PaymentBalance, dollars(4491), 75},
+		}},
+	}
+
+	for _, test := range tests {
+		details := paymentDetails(test.total)
+		details.PaymentPolicy = test.policy
+		if test.advance != 0 {
+			details.AdvancePaid, details.AdvancePaymentMode = dollars(test.advance), "Zelle"
+		}
+
+		schedule, err := NewSchedule(details)
+		if err != nil {
+			t.Errorf("%s : %v", test.name, err)
+			continue
+		}
+		checkPayments(t, test.name, schedule, test.want)
+		if schedule.Total != dollars(test.total) || schedule.Outstanding != dollars(test.total-test.advance) {
+			t.Errorf("%s : total %s with %s outstanding", test.name, schedule.Total, schedule.Outstanding)
+		}
+	}
+}
+
+func TestNewScheduleErrors(t *testing.T) {
+	tests := map[string]func(details *contract.PaymentDetails){
+		"unknown policy":      func(details *contract.PaymentDetails) { details.PaymentPolicy = "nope" },
+		"advance above total": func(details *contract.PaymentDetails) { details.AdvancePaid = dollars(2000) },
+		"negative advance":    func(details *contract.PaymentDetails) { details.AdvancePaid = dollars(-1) },
+		"no total":            func(details *contract.PaymentDetails) { details.TotalAmount = dollars(0) },
+		"installments under 100": func(details *contract.PaymentDetails) {
+			details.Installments = []contract.Installment{{Description: "First", Percent: 50}, {Description: "Second", Percent: 40}}
+		},
+	}
+
+	for name, change := range tests {
+		details := paymentDetails(1500)
+		change(&details)
+		if _, err := NewSchedule(details); err == nil {
+			t.Errorf("%s : no error", name)
+		}
+	}
+}
+
+func TestInstallmentSchedule(t *testing.T) {
+	details := paymentDetails(100.01)
+	details.Installments = []contract.Installment{
+		{Description: "Retainer", Percent: 50, DueDate: "2030-01-01", Modes: []string{"Zelle"}, Status: contract.InstallmentPaid},
+		{Description: "Final", Percent: 50, DueDate: "2030-05-01", Modes: []string{"Zelle", "Cash"}},
+	}
+
+	schedule, err := NewSchedule(details)
+	if err != nil {
+		t.Fatalf("NewSchedule : %v", err)
+	}
+
+	// Both halves round up, so the last one gives back the extra cent
+	checkPayments(t, "installments", schedule, []scheduled{
+		{PaymentInstallment, dollars(50.01), 50},
+		{PaymentInstallment, dollars(50), 50},
+	})
+	if schedule.Payments[1].Mode != "Zelle / Cash" || schedule.Payments[1].Due != "by 2030-05-01" {
+		t.Errorf("final installment is due %s by %s", schedule.Payments[1].Due, schedule.Payments[1].Mode)
+	}
+	if schedule.Received != dollars(50.01) || schedule.Outstanding != dollars(50) {
+		t.Errorf("received %s with %s outstanding", schedule.Received, schedule.Outstanding)
+	}
+}
+
+func TestScheduleFor(t *testing.T) {
+	record := contract.NewRecord(contract.Contract{PaymentDetails: paymentDetails(1500)})
+	record.Ledger = []contract.LedgerEntry{{Amount: dollars(500)}, {Amount: dollars(100)}}
+
+	schedule, err := ScheduleFor(record)
+	if err != nil {
+		t.Fatalf("ScheduleFor : %v", err)
+	}
+
+	// Payments are applied in order, the booking fee is covered and the rest goes to the balance
+	checkPayments(t, "ledger", schedule, []scheduled{
+		{PaymentBookingFee, dollars(380), 25},
+		{PaymentBalance, dollars(1120), 75},
+	})
+	booking, balance := schedule.Payments[0], schedule.Payments[1]
+	if !booking.Paid || booking.Received != dollars(380) {
+		t.Errorf("booking fee received %s, paid %v", booking.Received, booking.Paid)
+	}
+	if balance.Paid || balance.Received != dollars(220) {
+		t.Errorf("balance received %s, paid %v", balance.Received, balance.Paid)
+	}
+	if schedule.Total != dollars(1500) || schedule.Received != dollars(600) || schedule.Outstanding != dollars(900) {
+		t.Errorf("total %s, received %s, outstanding %s", schedule.Total, schedule.Received, schedule.Outstanding)
+	}
+}
+
+func TestInvoice(t *testing.T) {
+	details := paymentDetails(1500)
+	details.AdvancePaid, details.AdvancePaymentMode = dollars(100), "Zelle"
+	schedule, err := NewSchedule(details)
+	if err != nil {
+		t.Fatalf("NewSchedule : %v", err)
+	}
+
+	issuedAt := time.Date(2030, 1, 2, 15, 4, 5, 0, time.UTC)
+	invoice := schedule.Invoice(issuedAt)
+	if !invoice.IssuedAt.Equal(issuedAt) || invoice.Total != dollars(1500) || invoice.Received != dollars(100) || invoice.BalanceDue != dollars(1400) {
+		t.Errorf("invoice = %+v", invoice)
+	}
+
+	want := []contract.InvoiceLine{
+		{Description: "Advance Paid", Due: "already paid", Amount: dollars(100), Received: dollars(100)},
+		{Description: "Booking fee - 25%", Due: "at signing", Amount: dollars(280)},
+		{Description: "Remaining Project Payment - 75%", Due: "on the day of the event", Amount: dollars(1120)},
+	}
+	if len(invoice.Lines) != len(want) {
+		t.Fatalf("invoice has %d lines, want %d", len(invoice.Lines), len(want))
+	}
+	for i := range want {
+		line := invoice.Lines[i]
+		if line.Description != want[i].Description || line.Due != want[i].Due || line.Amount != want[i].Amount || line.Received.Amount != want[i].Received.Amount {
+			t.Errorf("line %d = %+v, want %+v", i+1, invoice.Lines[i], want[i])
+		}
+	}
+
+	// Payments recorded later do not change an invoice that was issued
+	schedule.ApplyLedger([]contract.LedgerEntry{{Amount: dollars(280)}})
+	if invoice.Received != dollars(100) || invoice.Lines[1].Received.Amount != 0 {
+		t.Error("the issued invoice changed with the schedule")
+	}
+}
+
+func TestScheduleForOvertime(t *testing.T) {
+	record := contract.NewRecord(contract.Contract{PaymentDetails: paymentDetails(1500)})
+	record.Overtime = []contract.Overtime{{Session: "Reception", Hours: 1.5, Amount: dollars(225)}}
+	record.Ledger = []contract.LedgerEntry{{Amount: dollars(1500)}, {Amount: dollars(100)}}
+
+	schedule, err := ScheduleFor(record)
+	if err != nil {
+		t.Fatalf("ScheduleFor : %v", err)
+	}
+
+	// Overtime is billed after the project payments and paid once they are
+	checkPayments(t, "overtime", schedule, []scheduled{
+		{PaymentBookingFee, dollars(380), 25},
+		{PaymentBalance, dollars(1120), 75},
+		{PaymentOvertime, dollars(225), 0},
+	})
+	if overtime := schedule.Payments[2]; overtime.Paid || overtime.Received != dollars(100) {
+		t.Errorf("overtime received %s, paid %v", overtime.Received, overtime.Paid)
+	}
+	if schedule.Total != dollars(1725) || schedule.Outstanding != dollars(125) {
+		t.Errorf("total %s with %s outstanding", schedule.Total, schedule.Outstanding)
+	}
+}
@@ -0,0 +1,229 @@
+package pricing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/contract"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/money"
+)
+
+const (
+	// ServicesTaxCategory is the tax category of the contract's services, and of line items that do not name one.
+	ServicesTaxCategory = "services"
+	// GoodsTaxCategory is for prints, albums and other goods, which are subject to sales tax.
+	GoodsTaxCategory = "goods"
+	// FeeTaxCategory is for travel fees, rush editing and other surcharges.
+	FeeTaxCategory = "fee"
+)
+
+var (
+	ErrUnknownTaxCategory = errors.New("unknown tax category")
+	ErrInvalidTaxRate     = errors.New("invalid tax rate")
+)
+
+// TaxRate is the tax charged on the line items of a tax category.
+type TaxRate struct {
+	// Name is how the tax is shown on the contract, e.g. Massachusetts sales tax.
+	Name    string  `json:"name,omitempty"`
+	Percent float64 `json:"percent"`
+}
+
+var (
+	taxRatesLock sync.RWMutex
+	// taxRates are the tax categories line items can name, keyed by category.
+	taxRates = map[string]TaxRate{
+		ServicesTaxCategory: {Percent: 0},
+		GoodsTaxCategory:    {Name: "Massachusetts sales tax", Percent: 6.25},
+		FeeTaxCategory:      {Percent: 0},
+	}
+)
+
+// LoadTaxRates adds the tax rates in a JSON file keyed by category, replacing built in rates of the same category.
+func LoadTaxRates(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed while reading tax rates file with err : %w", err)
+	}
+
+	var loaded map[string]TaxRate
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return fmt.Errorf("failed while parsing tax rates file with err : %w", err)
+	}
+
+	for category, rate := range loaded {
+		if rate.Percent < 0 || rate.Percent > 100 {
+			return fmt.Errorf("%w : tax rate of %s should be between 0 and 100", ErrInvalidTaxRate, category)
+		}
+		if rate.Percent != 0 && rate.Name == "" {
+			return fmt.Errorf("%w : tax rate of %s needs a name", ErrInvalidTaxRate, category)
+		}
+	}
+
+	taxRatesLock.Lock()
+	defer taxRatesLock.Unlock()
+	for category, rate := range loaded {
+		taxRates[category] = rate
+	}
+
+	return nil
+}
+
+// LookupTaxRate returns the tax rate of a category, the services category when it is empty.
+func LookupTaxRate(category string) (TaxRate, error) {
+	if category == "" {
+		category = ServicesTaxCategory
+	}
+
+	taxRatesLock.RLock()
+	defer taxRatesLock.RUnlock()
+
+	rate, ok := taxRates[category]
+	if !ok {
+		return TaxRate{}, fmt.Errorf("%w : %s", ErrUnknownTaxCategory, category)
+	}
+	return rate, nil
+}
+
+// Charge is one of the things a contract charges for, before tax.
+type Charge struct {
+	Description string      `json:"description"`
+	Amount      money.Money `json:"amount"`
+	TaxCategory string      `json:"taxCategory"`
+	Taxable     bool        `json:"taxable"`
+}
+
+// Tax is the tax charged on the charges of one tax category.
+type Tax struct {
+	Name        string      `json:"name"`
+	TaxCategory string      `json:"taxCategory"`
+	Percent     float64     `json:"percent"`
+	Taxable     money.Money `json:"taxable"`
+	Amount      money.Money `json:"amount"`
+}
+
+// Charges break the grand total of a contract down into its services, discount, line items and taxes.
+type Charges struct {
+	Items []Charge `json:"items"`
+	// Discount is taken off the services, DiscountLabel says what for.
+	Discount      money.Money `json:"discount"`
+	DiscountLabel string      `json:"discountLabel,omitempty"`
+	Subtotal      money.Money `json:"subtotal"`
+	Fees          money.Money `json:"fees"`
+	Taxes         []Tax       `json:"taxes,omitempty"`
+	Tax           money.Money `json:"tax"`
+	GrandTotal    money.Money `json:"grandTotal"`
+}
+
+// NewCharges takes the contract's discount off its services and adds the line items and the tax on them. Fees are
+// included in the subtotal and also counted on their own, as the accountant keeps track of them separately.
+func NewCharges(details contract.PaymentDetails) (*Charges, error) {
+	if details.TotalAmount.Amount <= 0 {
+		return nil, errors.New("total amount should be greater than zero")
+	}
+
+	charges := &Charges{
+		Items: []Charge{{
+			Description: "Photography and videography services",
+			Amount:      details.TotalAmount,
+			TaxCategory: ServicesTaxCategory,
+		}},
+		Discount: money.New(0, details.Currency),
+		Fees:     money.New(0, details.Currency),
+		Tax:      money.New(0, details.Currency),
+	}
+	if details.Discount != nil {
+		discount, err := discountOf(details.Discount, details.TotalAmount)
+		if err != nil {
+			return nil, err
+		}
+		charges.Discount = discount
+		charges.DiscountLabel = discountLabel(details.Discount)
+	}
+	for i, item := range details.LineItems {
+		if item.Description == "" {
+			return nil, fmt.Errorf("line item %d description is required", i+1)
+		}
+		if item.Amount.Amount <= 0 {
+			return nil, fmt.Errorf("line item %d amount should be greater than zero", i+1)
+		}
+		category := item.TaxCategory
+		if category == "" {
+			category = ServicesTaxCategory
+		}
+		if _, err := LookupTaxRate(category); err != nil {
+			return nil, fmt.Errorf("line item %d : %w", i+1, err)
+		}
+		charges.Items = append(charges.Items, Charge{
+			Description: item.Description,
+			Amount:      item.Amount,
+			TaxCategory: category,
+		})
+		if category == FeeTaxCategory {
+			charges.Fees = charges.Fees.Add(item.Amount)
+		}
+	}
+
+	// Tax is worked out on the total of each category, so it is only rounded once per category
+	taxable := map[string]money.Money{}
+	for i := range charges.Items {
+		item := &charges.Items[i]
+		// The categories of the line items were checked above and services are always known
+		rate, _ := LookupTaxRate(item.TaxCategory)
+		item.Taxable = rate.Percent != 0
+		charges.Subtotal = charges.Subtotal.Or(details.Currency).Add(item.Amount)
+		if item.Taxable {
+			taxable[item.TaxCategory] = taxable[item.TaxCategory].Or(details.Currency).Add(item.Amount)
+		}
+	}
+
+	categories := make([]string, 0, len(taxable))
+	for category := range taxable {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	for _, category := range categories {
+		rate, _ := LookupTaxRate(category)
+		tax := Tax{
+			Name:        rate.Name,
+			TaxCategory: category,
+			Percent:     rate.Percent,
+			Taxable:     taxable[category],
+			Amount:      taxable[category].Share(money.BasisPoints(rate.Percent)),
+		}
+		charges.Taxes = append(charges.Taxes, tax)
+		charges.Tax = charges.Tax.Add(tax.Amount)
+	}
+
+	charges.Subtotal = charges.Subtotal.Sub(charges.Discount)
+	charges.GrandTotal = charges.Subtotal.Add(charges.Tax)
+	return charges, nil
+}
+
+// Itemized is whether there is more to the charges than the services, so they are worth breaking down.
+func (charges *Charges) Itemized() bool {
+	return len(charges.Items) > 1 || !charges.Discount.IsZero()
+}
+
+// discountLabel describes a discount, as in "Discount - 10% (SPRING: Spring promotion)".
+func discountLabel(discount *contract.Discount) string {
+	label := "Discount"
+	if discount.Percent != 0 {
+		label += " - " + strconv.FormatFloat(discount.Percent, 'f', -1, 64) + "%"
+	}
+
+	reason := discount.Reason
+	if discount.Code != "" {
+		reason = strings.TrimSuffix(discount.Code+": "+reason, ": ")
+	}
+	if reason != "" {
+		label += " (" + reason + ")"
+	}
+	return label
+}
@@ -0,0 +1,158 @@
+package pricing
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/contract"
+)
+
+func TestNewCharges(t *testing.T) {
+	details := paymentDetails(1500)
+	details.LineItems = []contract.LineItem{
+		{Description: "Premium album", Amount: dollars(450), TaxCategory: GoodsTaxCategory},
+		{Description: "8x10 prints", Amount: dollars(120.5), TaxCategory: GoodsTaxCategory},
+		{Description: "Travel fee", Amount: dollars(75), TaxCategory: FeeTaxCategory},
+		{Description: "Second shooter", Amount: dollars(300)},
+	}
+
+	charges, err := NewCharges(details)
+	if err != nil {
+		t.Fatalf("NewCharges : %v", err)
+	}
+
+	if len(charges.Items) != 5 || !charges.Itemized() {
+		t.Fatalf("charges have %d items", len(charges.Items))
+	}
+	for i, taxable := range []bool{false, true, true, false, false} {
+		if charges.Items[i].Taxable != taxable {
+			t.Errorf("%s taxable = %v", charges.Items[i].Description, charges.Items[i].Taxable)
+		}
+	}
+	if charges.Items[4].TaxCategory != ServicesTaxCategory {
+		t.Errorf("line item without a category is in %q", charges.Items[4].TaxCategory)
+	}
+
+	// 6.25% of the 570.50 of goods is 35.65625, rounded once for the whole category
+	if len(charges.Taxes) != 1 || charges.Taxes[0].Taxable != dollars(570.5) || charges.Taxes[0].Amount != dollars(35.66) {
+		t.Errorf("taxes = %+v", charges.Taxes)
+	}
+	if charges.Subtotal != dollars(2445.5) || charges.Fees != dollars(75) || charges.Tax != dollars(35.66) || charges.GrandTotal != dollars(2481.16) {
+		t.Errorf("subtotal %s, fees %s, tax %s, grand total %s", charges.Subtotal, charges.Fees, charges.Tax, charges.GrandTotal)
+	}
+
+	// The booking fee is worked out on the grand total
+	schedule, err := NewSchedule(details)
+	if err != nil {
+		t.Fatalf("NewSchedule : %v", err)
+	}
+	checkPayments(t, "line items", schedule, []scheduled{
+		{PaymentBookingFee, dollars(630), 25},
+		{PaymentBalance, dollars(1851.16), 75},
+	})
+}
+
+func TestNewChargesDiscount(t *testing.T) {
+	tests := []struct {
+		name     string
+		discount contract.Discount
+		amount   float64
+		label    string
+	}{
+		{"percent", contract.Discount{Percent: 10, Reason: "Returning client"}, 150, "Discount - 10% (Returning client)"},
+		{"amount", contract.Discount{Amount: dollars(200), Reason: "Referral"}, 200, "Discount (Referral)"},
+		{"promo code", contract.Discount{Code: "SPRING", Percent: 12.5, Reason: "Spring promotion"}, 187.5, "Discount - 12.5% (SPRING: Spring promotion)"},
+	}
+
+	for _, test := range tests {
+		details := paymentDetails(1500)
+		details.Discount = &test.discount
+		details.LineItems = []contract.LineItem{{Description: "Premium album", Amount: dollars(400), TaxCategory: GoodsTaxCategory}}
+
+		charges, err := NewCharges(details)
+		if err != nil {
+			t.Errorf("%s : %v", test.name, err)
+			continue
+		}
+		// The discount comes off the services only, the album is taxed in full
+		if charges.Discount != dollars(test.amount) || charges.DiscountLabel != test.label {
+			t.Errorf("%s : discount %s labelled %q", test.name, charges.Discount, charges.DiscountLabel)
+		}
+		if charges.Tax != dollars(25) || charges.GrandTotal != dollars(1925-test.amount) {
+			t.Errorf("%s : tax %s, grand total %s", test.name, charges.Tax, charges.GrandTotal)
+		}
+	}
+
+	for name, discount := range map[string]contract.Discount{
+		"more than the total": {Amount: dollars(1501), Reason: "Too much"},
+		"both kinds":          {Percent: 10, Amount: dollars(10), Reason: "Both"},
+		"above 100%":          {Percent: 101, Reason: "Too much"},
+		"nothing off":         {Reason: "Nothing"},
+	} {
+		details := paymentDetails(1500)
+		details.Discount = &discount
+		if _, err := NewCharges(details); !errors.Is(err, ErrInvalidDiscount) {
+			t.Errorf("%s : NewCharges returned %v, want ErrInvalidDiscount", name, err)
+		}
+	}
+}
+
+func TestNewChargesErrors(t *testing.T) {
+	details := paymentDetails(1500)
+	details.LineItems = []contract.LineItem{
+		{Description: "Premium album", Amount: dollars(450), TaxCategory: GoodsTaxCategory},
+		{Description: "Mystery", Amount: dollars(10), TaxCategory: "nope"},
+	}
+	_, err := NewCharges(details)
+	if !errors.Is(err, ErrUnknownTaxCategory) || err.Error() != "line item 2 : unknown tax category : nope" {
+		t.Errorf("unknown category returned %v", err)
+	}
+
+	details.LineItems = []contract.LineItem{{Description: "Free prints", Amount: dollars(0)}}
+	if _, err := NewCharges(details); err == nil || err.Error() != "line item 1 amount should be greater than zero" {
+		t.Errorf("free line item returned %v", err)
+	}
+}
+
+func TestLoadTaxRates(t *testing.T) {
+	// The loaded rates are put back as they were for the other tests
+	taxRatesLock.RLock()
+	builtIn := make(map[string]TaxRate, len(taxRates))
+	for category, rate := range taxRates {
+		builtIn[category] = rate
+	}
+	taxRatesLock.RUnlock()
+	t.Cleanup(func() {
+		taxRatesLock.Lock()
+		taxRates = builtIn
+		taxRatesLock.Unlock()
+	})
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	if err := LoadTaxRates(write("bad.json", `{"rush": {"percent": 5}}`)); !errors.Is(err, ErrInvalidTaxRate) {
+		t.Errorf("unnamed tax rate returned %v, want ErrInvalidTaxRate", err)
+	}
+	if err := LoadTaxRates(write("rates.json", `{"rush": {"name": "Rush tax", "percent": 5}}`)); err != nil {
+		t.Fatalf("LoadTaxRates : %v", err)
+	}
+
+	details := paymentDetails(1000)
+	details.LineItems = []contract.LineItem{{Description: "Rush editing", Amount: dollars(200), TaxCategory: "rush"}}
+	charges, err := NewCharges(details)
+	if err != nil {
+		t.Fatalf("NewCharges : %v", err)
+	}
+	if charges.Tax != dollars(10) || charges.Taxes[0].Name != "Rush tax" {
+		t.Errorf("taxes = %+v", charges.Taxes)
+	}
+}
@@ -0,0 +1,86 @@
+package signing
+
+import (
+	"crypto/rand"
+	"embed"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// maxDrawingSize bounds the decoded size of a drawn signature.
+const maxDrawingSize = 512 * 1024
+
+const pngDataURLPrefix = "data:image/png;base64,"
+
+var ErrInvalidDrawing = errors.New("drawn signature is not a valid png image")
+
+//go:embed templates/*.html
+var templates embed.FS
+
+var signPage = template.Must(template.ParseFS(templates, "templates/sign.html"))
+
+// Page is what the signing page shows to the client.
+type Page struct {
+	// StudioName is the name of the studio in its branding, the agreement is titled with it.
+	StudioName   string
+	ContractID   string
+	ClientName   string
+	EventName    string
+	DocumentURL  string
+	SignedURL    string
+	SubmitURL    string
+	DocumentHash string
+	Error        string
+	// Signed is set once the contract has been signed, the form is replaced by a confirmation.
+	Signed     bool
+	SignedName string
+	SignedAt   string
+}
+
+// NewToken returns a random token for a contract signing link.
+func NewToken() (string, error) {
+	token := make([]byte, 32)
+	_, err := rand.Read(token)
+	if err != nil {
+		return "", fmt.Errorf("failed while generating a signing token with err : %w", err)
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// RenderPage writes the signing page as HTML.
+func RenderPage(w io.Writer, page *Page) error {
+	err := signPage.Execute(w, page)
+	if err != nil {
+		return fmt.Errorf("failed while rendering the signing page with err : %w", err)
+	}
+	return nil
+}
+
+// ParseDrawing validates a PNG data URL captured by the signature pad and returns its base64 content.
+// An empty data URL means the client typed their signature instead.
+func ParseDrawing(dataURL string) (string, error) {
+	if dataURL == "" {
+		return "", nil
+	}
+
+	if !strings.HasPrefix(dataURL, pngDataURLPrefix) {
+		return "", ErrInvalidDrawing
+	}
+
+	encoded := strings.TrimPrefix(dataURL, pngDataURLPrefix)
+	if base64.StdEncoding.DecodedLen(len(encoded)) > maxDrawingSize {
+		return "", fmt.Errorf("%w : drawing is larger than %d bytes", ErrInvalidDrawing, maxDrawingSize)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || !strings.HasPrefix(string(decoded), "\x89PNG\r\n\x1a\n") {
+		return "", ErrInvalidDrawing
+	}
+
+	return encoded, nil
+}
@@ -0,0 +1,67 @@
+package signing
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const pngHeader = "\x89PNG\r\n\x1a\n"
+
+func TestParseDrawing(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte(pngHeader + "image data"))
+	drawing, err := ParseDrawing(pngDataURLPrefix + encoded)
+	if err != nil || drawing != encoded {
+		t.Errorf("ParseDrawing = %q, %v", drawing, err)
+	}
+
+	// Typed signatures have no drawing
+	if drawing, err := ParseDrawing(""); err != nil || drawing != "" {
+		t.Errorf("ParseDrawing of nothing = %q, %v", drawing, err)
+	}
+
+	tests := map[string]string{
+		"not a data url": encoded,
+		"jpeg":           "data:image/jpeg;base64," + encoded,
+		"not base64":     pngDataURLPrefix + "not base64!",
+		"not a png":      pngDataURLPrefix + base64.StdEncoding.EncodeToString([]byte("GIF89a")),
+		"too large":      pngDataURLPrefix + base64.StdEncoding.EncodeToString([]byte(pngHeader+strings.Repeat("x", maxDrawingSize))),
+	}
+	for name, dataURL := range tests {
+		if _, err := ParseDrawing(dataURL); !errors.Is(err, ErrInvalidDrawing) {
+			t.Errorf("%s : ParseDrawing returned %v, want ErrInvalidDrawing", name, err)
+		}
+	}
+}
+
+func TestNewToken(t *testing.T) {
+	first, err := NewToken()
+	if err != nil {
+		t.Fatalf("NewToken : %v", err)
+	}
+	second, _ := NewToken()
+	if len(first) != 64 || first == second {
+		t.Errorf("tokens %q and %q", first, second)
+	}
+}
+
+func TestRenderPage(t *testing.T) {
+	var page bytes.Buffer
+	err := RenderPage(&page, &Page{
+		StudioName:   "Blue Lens",
+		ClientName:   "Priya <Raman>",
+		SubmitURL:    "/sign/id/token",
+		DocumentHash: "abc123",
+	})
+	if err != nil {
+		t.Fatalf("RenderPage : %v", err)
+	}
+
+	for _, want := range []string{"Blue Lens Services Agreement", `value="abc123"`, "Priya &lt;Raman&gt;"} {
+		if !strings.Contains(page.String(), want) {
+			t.Errorf("signing page does not contain %q", want)
+		}
+	}
+}
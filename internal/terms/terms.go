@@ -0,0 +1,358 @@
+package terms
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/branding"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/contract"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// LegacyVersion is the version of the terms contracts were issued under before terms were versioned.
+	LegacyVersion = "v1"
+	// DefaultVersion is the version new contracts are issued under unless another one is made current.
+	DefaultVersion = "v4"
+)
+
+var (
+	ErrUnknownVersion       = errors.New("unknown terms version")
+	ErrInvalidTemplate      = errors.New("invalid terms template")
+	ErrInvalidClause        = errors.New("invalid clause")
+	ErrClauseNotOverridable = errors.New("clause cannot be overridden")
+)
+
+//go:embed templates/*.yaml
+var builtInTemplates embed.FS
+
+// Template is one version of the terms and conditions. Clause bodies are text/template templates filled in with
+// Data.
+type Template struct {
+	Version string           `yaml:"version" json:"version"`
+	Title   string           `yaml:"title" json:"title"`
+	Clauses []ClauseTemplate `yaml:"clauses" json:"clauses"`
+}
+
+// ClauseTemplate is a clause of the terms, Key identifies it across versions.
+type ClauseTemplate struct {
+	Key     string `yaml:"key" json:"key"`
+	Heading string `yaml:"heading" json:"heading"`
+	// EventTypes are the types of events the clause applies to, every type when empty.
+	EventTypes []contract.EventType `yaml:"eventTypes,omitempty" json:"eventTypes,omitempty"`
+	// Overridable clauses can be replaced by conditions negotiated for a contract.
+	Overridable bool   `yaml:"overridable,omitempty" json:"overridable"`
+	Body        string `yaml:"body" json:"body"`
+
+	body *template.Template
+}
+
+// Clause is a clause of the terms filled in for a contract.
+type Clause struct {
+	Key     string
+	Heading string
+	Body    string
+	// Custom clauses were written for the contract, as overrides or additional clauses.
+	Custom bool
+}
+
+// Data is what clause bodies can refer to, as in {{.PerHourExtra}}.
+type Data struct {
+	ClientName   string
+	EventName    string
+	PerHourExtra string
+	// StudioName is the name of the studio in its branding, for terms written for white labelled studios.
+	StudioName string
+}
+
+var (
+	templatesLock sync.RWMutex
+	// templates are the versions of the terms, keyed by version.
+	templates = map[string]*Template{}
+	// current is the version new contracts are issued under.
+	current = DefaultVersion
+)
+
+func init() {
+	if err := loadTemplates(builtInTemplates, "templates"); err != nil {
+		panic(fmt.Sprintf("failed while loading the built in terms with err : %v", err))
+	}
+}
+
+// LoadTemplates adds the versions of the terms in the YAML files of a directory. Contracts are rendered with the
+// version they were issued under, so a version that is already defined, built in ones included, can only be loaded
+// again as it is.
+func LoadTemplates(dir string) error {
+	return loadTemplates(os.DirFS(dir), ".")
+}
+
+func loadTemplates(fsys fs.FS, dir string) error {
+	paths, err := fs.Glob(fsys, filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return fmt.Errorf("failed while listing terms templates with err : %w", err)
+	}
+
+	loaded := map[string]*Template{}
+	for _, path := range paths {
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return fmt.Errorf("failed while reading terms template %s with err : %w", path, err)
+		}
+
+		var terms Template
+		if err := yaml.Unmarshal(data, &terms); err != nil {
+			return fmt.Errorf("failed while parsing terms template %s with err : %w", path, err)
+		}
+		if err := terms.parse(); err != nil {
+			return fmt.Errorf("terms template %s : %w", path, err)
+		}
+		if loaded[terms.Version] != nil {
+			return fmt.Errorf("%w : version %s is defined more than once", ErrInvalidTemplate, terms.Version)
+		}
+		loaded[terms.Version] = &terms
+	}
+
+	templatesLock.Lock()
+	defer templatesLock.Unlock()
+	for version, terms := range loaded {
+		if existing := templates[version]; existing != nil && !existing.sameAs(terms) {
+			return fmt.Errorf("%w : version %s is already defined with other terms, new terms need a new version", ErrInvalidTemplate, version)
+		}
+	}
+	for version, terms := range loaded {
+		templates[version] = terms
+	}
+
+	return nil
+}
+
+// sameAs reports whether both templates have the same title and clauses.
+func (terms *Template) sameAs(other *Template) bool {
+	data, err := json.Marshal(terms)
+	if err != nil {
+		return false
+	}
+	otherData, err := json.Marshal(other)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(data, otherData)
+}
+
+// SetCurrent makes the given version the one new contracts are issued under.
+func SetCurrent(version string) error {
+	templatesLock.Lock()
+	defer templatesLock.Unlock()
+
+	if templates[version] == nil {
+		return fmt.Errorf("%w : %s", ErrUnknownVersion, version)
+	}
+	current = version
+	return nil
+}
+
+// Current is the version new contracts are issued under.
+func Current() string {
+	templatesLock.RLock()
+	defer templatesLock.RUnlock()
+
+	return current
+}
+
+// Versions lists the versions of the terms that are loaded.
+func Versions() []string {
+	templatesLock.RLock()
+	defer templatesLock.RUnlock()
+
+	versions := make([]string, 0, len(templates))
+	for version := range templates {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// Lookup returns the given version of the terms, the legacy version when it is empty.
+func Lookup(version string) (*Template, error) {
+	if version == "" {
+		version = LegacyVersion
+	}
+
+	templatesLock.RLock()
+	defer templatesLock.RUnlock()
+
+	terms, ok := templates[version]
+	if !ok {
+		return nil, fmt.Errorf("%w : %s", ErrUnknownVersion, version)
+	}
+	return terms, nil
+}
+
+// Render fills in the clauses of the terms that apply to the contract's type of event, replacing those it overrides
+// and adding its additional clauses after them. The studio is the one the contract was issued by.
+func (terms *Template) Render(details *contract.Contract, studio branding.Studio) ([]Clause, error) {
+	eventType := details.EventDetails.EventType
+	if eventType == "" {
+		eventType = contract.EventTypeEvent
+	}
+	data := Data{
+		ClientName:   details.ClientDetails.ClientName,
+		EventName:    details.EventDetails.EventName,
+		PerHourExtra: details.PaymentDetails.PerHourExtra.String(),
+		StudioName:   studio.Name,
+	}
+
+	overrides := map[string]contract.CustomClause{}
+	for _, override := range details.ClauseOverrides {
+		overrides[override.Key] = override
+	}
+
+	clauses := make([]Clause, 0, len(terms.Clauses)+len(details.AdditionalClauses))
+	for _, clause := range terms.Clauses {
+		if !clause.AppliesTo(eventType) {
+			continue
+		}
+
+		if override, ok := overrides[clause.Key]; ok {
+			heading := override.Heading
+			if heading == "" {
+				heading = clause.Heading
+			}
+			clauses = append(clauses, Clause{Key: clause.Key, Heading: heading, Body: override.Body, Custom: true})
+			continue
+		}
+
+		var body bytes.Buffer
+		if err := clause.body.Execute(&body, data); err != nil {
+			return nil, fmt.Errorf("failed while filling in the %s clause of terms %s with err : %w", clause.Key, terms.Version, err)
+		}
+		clauses = append(clauses, Clause{Key: clause.Key, Heading: clause.Heading, Body: body.String()})
+	}
+
+	for _, additional := range details.AdditionalClauses {
+		clauses = append(clauses, Clause{Heading: additional.Heading, Body: additional.Body, Custom: true})
+	}
+	return clauses, nil
+}
+
+// ValidateCustomClauses checks the contract only overrides clauses of these terms that apply to it and are
+// overridable, once each, and that its additional clauses have a heading and a body.
+func (terms *Template) ValidateCustomClauses(details *contract.Contract) error {
+	eventType := details.EventDetails.EventType
+	if eventType == "" {
+		eventType = contract.EventTypeEvent
+	}
+
+	overridden := map[string]bool{}
+	for i, override := range details.ClauseOverrides {
+		if override.Key == "" {
+			return fmt.Errorf("%w : clause override %d needs the key of the clause it replaces", ErrInvalidClause, i+1)
+		}
+		if strings.TrimSpace(override.Body) == "" {
+			return fmt.Errorf("%w : the override of the %s clause needs a body", ErrInvalidClause, override.Key)
+		}
+		if overridden[override.Key] {
+			return fmt.Errorf("%w : the %s clause is overridden more than once", ErrInvalidClause, override.Key)
+		}
+		overridden[override.Key] = true
+
+		clause, ok := terms.clause(override.Key)
+		if !ok || !clause.AppliesTo(eventType) {
+			return fmt.Errorf("%w : terms %s have no %s clause for %s contracts", ErrInvalidClause, terms.Version, override.Key, eventType)
+		}
+		if !clause.Overridable {
+			return fmt.Errorf("%w : %s", ErrClauseNotOverridable, override.Key)
+		}
+	}
+
+	for i, additional := range details.AdditionalClauses {
+		if additional.Key != "" {
+			return fmt.Errorf("%w : additional clause %d cannot have a key, clauses are overridden through clauseOverrides", ErrInvalidClause, i+1)
+		}
+		if additional.Heading == "" || strings.TrimSpace(additional.Body) == "" {
+			return fmt.Errorf("%w : additional clause %d needs a heading and a body", ErrInvalidClause, i+1)
+		}
+	}
+	return nil
+}
+
+func (terms *Template) clause(key string) (ClauseTemplate, bool) {
+	for _, clause := range terms.Clauses {
+		if clause.Key == key {
+			return clause, true
+		}
+	}
+	return ClauseTemplate{}, false
+}
+
+// AppliesTo is whether the clause is part of the terms for the given type of event.
+func (clause ClauseTemplate) AppliesTo(eventType contract.EventType) bool {
+	if len(clause.EventTypes) == 0 {
+		return true
+	}
+	for _, applies := range clause.EventTypes {
+		if applies == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// parse checks the template and parses the clause bodies, so mistakes show up when the terms are loaded rather
+// than when a contract is issued.
+func (terms *Template) parse() error {
+	switch {
+	case terms.Version == "":
+		return fmt.Errorf("%w : a version is required", ErrInvalidTemplate)
+	case terms.Title == "":
+		return fmt.Errorf("%w : a title is required", ErrInvalidTemplate)
+	case len(terms.Clauses) == 0:
+		return fmt.Errorf("%w : at least one clause is required", ErrInvalidTemplate)
+	}
+
+	keys := map[string]bool{}
+	bodies := map[string]string{}
+	for i := range terms.Clauses {
+		clause := &terms.Clauses[i]
+		if clause.Key == "" || clause.Heading == "" || strings.TrimSpace(clause.Body) == "" {
+			return fmt.Errorf("%w : clause %d needs a key, heading and body", ErrInvalidTemplate, i+1)
+		}
+		if keys[clause.Key] {
+			return fmt.Errorf("%w : there is more than one %s clause", ErrInvalidTemplate, clause.Key)
+		}
+		keys[clause.Key] = true
+
+		for _, eventType := range clause.EventTypes {
+			if _, err := contract.ParseEventType(string(eventType)); err != nil || eventType == "" {
+				return fmt.Errorf("%w : %s clause : event type %q is not known", ErrInvalidTemplate, clause.Key, eventType)
+			}
+		}
+
+		// Two clauses with the same text is a copy and paste mistake, except in the legacy terms that were
+		// signed that way
+		if other, ok := bodies[clause.Body]; ok && terms.Version != LegacyVersion {
+			return fmt.Errorf("%w : the %s clause repeats the %s clause", ErrInvalidTemplate, clause.Key, other)
+		}
+		bodies[clause.Body] = clause.Key
+
+		body, err := template.New(clause.Key).Option("missingkey=error").Parse(clause.Body)
+		if err != nil {
+			return fmt.Errorf("%w : %s clause : %s", ErrInvalidTemplate, clause.Key, err)
+		}
+		if err := body.Execute(&bytes.Buffer{}, Data{}); err != nil {
+			return fmt.Errorf("%w : %s clause : %s", ErrInvalidTemplate, clause.Key, err)
+		}
+		clause.body = body
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package terms
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/branding"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/contract"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/money"
+)
+
+func testContract(eventType contract.EventType) *contract.Contract {
+	return &contract.Contract{
+		ClientDetails: contract.ClientDetails{ClientName: "Priya Raman"},
+		EventDetails:  contract.EventDetails{EventName: "House Warming", EventType: eventType},
+		PaymentDetails: contract.PaymentDetails{
+			TotalAmount:  money.FromMajor(1500, money.DefaultCurrency),
+			PerHourExtra: money.FromMajor(150, money.DefaultCurrency),
+		},
+	}
+}
+
+// testStudio is who the test contracts were issued by.
+var testStudio = branding.Studio{Name: "Lumen Films", LegalName: "Lumen Films LLC", Location: "Rhode Island"}
+
+func clauseKeys(clauses []Clause) []string {
+	keys := make([]string, 0, len(clauses))
+	for _, clause := range clauses {
+		keys = append(keys, clause.Key)
+	}
+	return keys
+}
+
+func TestRenderByEventType(t *testing.T) {
+	terms, err := Lookup(DefaultVersion)
+	if err != nil {
+		t.Fatalf("Lookup : %v", err)
+	}
+
+	tests := []struct {
+		eventType contract.EventType
+		has       []string
+		hasNot    []string
+	}{
+		{contract.EventTypeWedding, []string{"reschedule", "wedding-revisions", "album", "copyright"}, []string{"reschedule-short", "usage-license", "invoicing"}},
+		{contract.EventTypeCorporate, []string{"video-modifications", "usage-license", "invoicing"}, []string{"album", "copyright"}},
+		{contract.EventTypeSmall, []string{"reschedule-short", "copyright"}, []string{"reschedule", "additional-services"}},
+		// Contracts that do not name a type are events
+		{"", []string{"reschedule", "video-modifications"}, []string{"album", "invoicing", "reschedule-short"}},
+	}
+
+	for _, test := range tests {
+		clauses, err := terms.Render(testContract(test.eventType), testStudio)
+		if err != nil {
+			t.Errorf("%q : Render : %v", test.eventType, err)
+			continue
+		}
+		keys := "," + strings.Join(clauseKeys(clauses), ",") + ","
+		for _, key := range test.has {
+			if !strings.Contains(keys, ","+key+",") {
+				t.Errorf("%q contracts are missing the %s clause", test.eventType, key)
+			}
+		}
+		for _, key := range test.hasNot {
+			if strings.Contains(keys, ","+key+",") {
+				t.Errorf("%q contracts have the %s clause", test.eventType, key)
+			}
+		}
+	}
+}
+
+func TestRenderFillsInTemplates(t *testing.T) {
+	for _, version := range []string{"v2", "v3", "v4"} {
+		terms, err := Lookup(version)
+		if err != nil {
+			t.Fatalf("Lookup : %v", err)
+		}
+		clauses, err := terms.Render(testContract(contract.EventTypeEvent), testStudio)
+		if err != nil {
+			t.Fatalf("%s : Render : %v", version, err)
+		}
+
+		reschedule := clauses[0]
+		if !strings.Contains(reschedule.Body, "$150.00/hr") {
+			t.Errorf("%s : reschedule clause reads %q", version, reschedule.Body)
+		}
+		// Published terms are not changed, the studio is only named after the branding from v4 on
+		if names := strings.Contains(reschedule.Body, "Lumen Films"); names != (version == "v4") || names == strings.Contains(reschedule.Body, "Red Dot") {
+			t.Errorf("%s : reschedule clause reads %q", version, reschedule.Body)
+		}
+		for _, clause := range clauses {
+			if strings.Contains(clause.Body, "{{") || clause.Custom {
+				t.Errorf("%s : %s clause was not filled in : %q", version, clause.Key, clause.Body)
+			}
+		}
+	}
+
+	// Contracts issued before terms were versioned are rendered with the legacy terms as they were signed
+	legacy, err := Lookup("")
+	if err != nil || legacy.Version != LegacyVersion {
+		t.Fatalf("Lookup of no version = %v, %v", legacy, err)
+	}
+	if _, err := Lookup("v0"); !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("Lookup(v0) returned %v, want ErrUnknownVersion", err)
+	}
+}
+
+func TestRenderCustomClauses(t *testing.T) {
+	terms, _ := Lookup(DefaultVersion)
+	details := testContract(contract.EventTypeWedding)
+	details.ClauseOverrides = []contract.CustomClause{{Key: "copyright", Body: "No watermark on social previews."}}
+	details.AdditionalClauses = []contract.CustomClause{{Heading: "Drone Footage:", Body: "Client may not use drone footage commercially."}}
+
+	if err := terms.ValidateCustomClauses(details); err != nil {
+		t.Fatalf("ValidateCustomClauses : %v", err)
+	}
+	clauses, err := terms.Render(details, testStudio)
+	if err != nil {
+		t.Fatalf("Render : %v", err)
+	}
+
+	copyright, _ := terms.clause("copyright")
+	for _, clause := range clauses {
+		if clause.Key == "copyright" && (clause.Body != "No watermark on social previews." || clause.Heading != copyright.Heading || !clause.Custom) {
+			t.Errorf("overridden clause = %+v", clause)
+		}
+	}
+	last := clauses[len(clauses)-1]
+	if last.Heading != "Drone Footage:" || last.Key != "" || !last.Custom {
+		t.Errorf("last clause = %+v, want the additional clause", last)
+	}
+}
+
+func TestValidateCustomClauses(t *testing.T) {
+	terms, _ := Lookup(DefaultVersion)
+
+	tests := []struct {
+		name       string
+		eventType  contract.EventType
+		overrides  []contract.CustomClause
+		additional []contract.CustomClause
+		want       error
+	}{
+		{"not overridable", contract.EventTypeWedding, []contract.CustomClause{{Key: "cancellation", Body: "Anytime."}}, nil, ErrClauseNotOverridable},
+		{"not for the event type", contract.EventTypeCorporate, []contract.CustomClause{{Key: "album", Body: "Two albums."}}, nil, ErrInvalidClause},
+		{"unknown key", contract.EventTypeWedding, []contract.CustomClause{{Key: "pets", Body: "Dogs welcome."}}, nil, ErrInvalidClause},
+		{"twice", contract.EventTypeWedding, []contract.CustomClause{{Key: "album", Body: "One."}, {Key: "album", Body: "Two."}}, nil, ErrInvalidClause},
+		{"no body", contract.EventTypeWedding, []contract.CustomClause{{Key: "album", Body: " "}}, nil, ErrInvalidClause},
+		{"additional with a key", contract.EventTypeWedding, nil, []contract.CustomClause{{Key: "album", Heading: "Album", Body: "One."}}, ErrInvalidClause},
+		{"additional without a heading", contract.EventTypeWedding, nil, []contract.CustomClause{{Body: "One."}}, ErrInvalidClause},
+	}
+
+	for _, test := range tests {
+		details := testContract(test.eventType)
+		details.ClauseOverrides, details.AdditionalClauses = test.overrides, test.additional
+		if err := terms.ValidateCustomClauses(details); !errors.Is(err, test.want) {
+			t.Errorf("%s : ValidateCustomClauses returned %v, want %v", test.name, err, test.want)
+		}
+	}
+}
+
+func writeTemplate(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadTemplates(t *testing.T) {
+	builtIn, err := builtInTemplates.ReadFile("templates/v3.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A copy of a version that is already defined loads as long as it is the same
+	same := t.TempDir()
+	writeTemplate(t, same, "v3.yaml", string(builtIn))
+	if err := LoadTemplates(same); err != nil {
+		t.Errorf("loading an unchanged copy of v3 : %v", err)
+	}
+
+	changed := t.TempDir()
+	writeTemplate(t, changed, "v3.yaml", strings.Replace(string(builtIn), "Reschedule Policy", "Rescheduling", 1))
+	writeTemplate(t, changed, "v9.yaml", "version: test-v9\ntitle: Terms\nclauses:\n  - key: a\n    heading: A\n    body: Body\n")
+	if err := LoadTemplates(changed); !errors.Is(err, ErrInvalidTemplate) {
+		t.Fatalf("loading other terms as v3 returned %v, want ErrInvalidTemplate", err)
+	}
+	if _, err := Lookup("test-v9"); !errors.Is(err, ErrUnknownVersion) {
+		t.Error("a directory that failed to load was loaded in part")
+	}
+	terms, _ := Lookup("v3")
+	if terms.Clauses[0].Heading != "Reschedule Policy" {
+		t.Errorf("v3 was changed to %q", terms.Clauses[0].Heading)
+	}
+
+	added := t.TempDir()
+	writeTemplate(t, added, "v8.yaml", `version: test-v8
+title: Terms and Conditions
+clauses:
+  - key: event
+    heading: Event
+    body: "We cover {{.EventName}} for {{.ClientName}}."
+`)
+	if err := LoadTemplates(added); err != nil {
+		t.Fatalf("LoadTemplates : %v", err)
+	}
+	if err := SetCurrent("test-v8"); err != nil {
+		t.Fatalf("SetCurrent : %v", err)
+	}
+	defer SetCurrent(DefaultVersion)
+
+	current, _ := Lookup(Current())
+	clauses, err := current.Render(testContract(contract.EventTypeEvent), testStudio)
+	if err != nil || len(clauses) != 1 || clauses[0].Body != "We cover House Warming for Priya Raman." {
+		t.Errorf("Render = %+v, %v", clauses, err)
+	}
+
+	if err := SetCurrent("test-v0"); !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("SetCurrent of an unknown version returned %v", err)
+	}
+}
+
+func TestLoadTemplatesInvalid(t *testing.T) {
+	tests := map[string]string{
+		"no title":            "version: bad\nclauses:\n  - key: a\n    heading: A\n    body: Body\n",
+		"no clauses":          "version: bad\ntitle: Terms\n",
+		"clause without body": "version: bad\ntitle: Terms\nclauses:\n  - key: a\n    heading: A\n",
+		"same key twice":      "version: bad\ntitle: Terms\nclauses:\n  - key: a\n    heading: A\n    body: One\n  - key: a\n    heading: B\n    body: Two\n",
+		"repeated body":       "version: bad\ntitle: Terms\nclauses:\n  - key: a\n    heading: A\n    body: Same\n  - key: b\n    heading: B\n    body: Same\n",
+		"unknown event type":  "version: bad\ntitle: Terms\nclauses:\n  - key: a\n    heading: A\n    eventTypes: [party]\n    body: Body\n",
+		"unknown field":       "version: bad\ntitle: Terms\nclauses:\n  - key: a\n    heading: A\n    body: \"{{.Venue}}\"\n",
+	}
+
+	for name, content := range tests {
+		dir := t.TempDir()
+		writeTemplate(t, dir, "bad.yaml", content)
+		if err := LoadTemplates(dir); !errors.Is(err, ErrInvalidTemplate) {
+			t.Errorf("%s : LoadTemplates returned %v, want ErrInvalidTemplate", name, err)
+		}
+	}
+}
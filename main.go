@@ -4,77 +4,118 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/sirupsen/logrus"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/branding"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/catalog"
 	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/gformscreator"
 	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/imagecreator"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/money"
 	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/pdfcreator"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/pricing"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/terms"
 
 	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/contract"
 )
 
+var (
+	// contractStore keeps a record of every contract issued by NewContractHandler.
+	contractStore contract.ContractStore
+	// fileStore and formBuilder create the contract forms, they are Google Drive and Forms outside of tests.
+	fileStore   gformscreator.FileStore
+	formBuilder gformscreator.FormBuilder
+)
+
 func NewContractHandler(c *fiber.Ctx) error {
 	logger := logrus.New()
 	logger.Info("Handling request")
 	defer logger.Info("Finished handling request")
-	var contract contract.Contract
-	if err := c.BodyParser(&contract); err != nil {
+	var details contract.Contract
+	if err := c.BodyParser(&details); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Failed to parse JSON",
 		})
 	}
 
-	if err := ValidateContract(&contract); err != nil {
+	// Contracts made from a package only need to send what differs from it. Split events take their date and
+	// coverage from their first session, so the package has them to go by.
+	details.EventDetails.UseFirstSession()
+	if err := catalog.Apply(&details); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
-	contractsFileName, err := pdfcreator.CreateContractsPage(&contract)
-	if err != nil {
-		logger.WithError(err).Errorf("failed while creating pdf for contract")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Errorf("failed while creating pdf for contract : %w", err).Error(),
+	if err := ValidateContract(&details); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
 		})
 	}
 
-	termsFileName, err := pdfcreator.CreateTermsPage(&contract)
+	schedule, err := pricing.NewSchedule(details.PaymentDetails)
 	if err != nil {
-		logger.WithError(err).Errorf("failed while creating pdf for terms")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Errorf("failed while creating pdf for terms file : %w", err).Error(),
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
 		})
 	}
 
-	err = imagecreator.ImageCreator(imagecreator.Contract, contractsFileName)
-	if err != nil {
-		logger.WithError(err).Errorf("failed while creating an image for contracts file")
+	record := contract.NewRecord(details)
+	record.TermsVersion = terms.Current()
+	studio := branding.Current().Studio
+	record.Studio = &studio
+
+	// Previews return the rendered contract without creating a form or saving it
+	if c.QueryBool("preview") {
+		return sendContractPdf(c, record, "inline", "contract-preview")
+	}
+
+	if err := redeemPromoCode(details.PaymentDetails.Discount); err != nil {
+		if errors.Is(err, contract.ErrPromoCodeUsedUp) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		logger.WithError(err).Errorf("failed while redeeming promo code")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Errorf("failed while creating an image for terms file : %w", err).Error(),
+			"error": fmt.Errorf("failed while redeeming promo code : %w", err).Error(),
 		})
 	}
 
-	err = imagecreator.ImageCreator(imagecreator.Terms, termsFileName)
+	// The use of the promo code is given back when the contract does not make it into the store
+	saved := false
+	defer func() {
+		if saved {
+			return
+		}
+		if err := releasePromoCode(details.PaymentDetails.Discount); err != nil {
+			logger.WithError(err).Errorf("failed while giving back the use of promo code")
+		}
+	}()
+
+	document, err := pdfcreator.RenderContract(record)
 	if err != nil {
-		logger.WithError(err).Errorf("failed while creating an image for terms file")
+		logger.WithError(err).Errorf("failed while creating pdf for contract")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Errorf("failed while creating an image for terms file : %w", err).Error(),
+			"error": fmt.Errorf("failed while creating pdf for contract : %w", err).Error(),
 		})
 	}
 
-	err = pdfcreator.CleanUpPdfs()
+	images, err := imagecreator.ImageCreator(document)
 	if err != nil {
-		logger.WithError(err).Errorf("failed while cleaning up pdfs")
+		logger.WithError(err).Errorf("failed while creating images for contract file")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Errorf("failed while cleaning up pdfs with err : %w", err).Error(),
+			"error": fmt.Errorf("failed while creating images for contract file : %w", err).Error(),
 		})
 	}
 
-	err = gformscreator.CreateGoogleForm(&contract)
+	form, err := gformscreator.CreateGoogleForm(fileStore, formBuilder, &details, schedule, images)
 	if err != nil {
 		logger.WithError(err).Errorf("failed while creating google form")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -82,26 +123,167 @@ func NewContractHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	err = imagecreator.CleanUpImages()
+	record.FormID = form.FormID
+	record.FormURL = form.ResponderURI
+	record.FormEditURL = form.EditURI
+	record.FormImageFileIDs = form.ImageFileIDs
+	record.FormSignatureQuestionID = form.SignatureQuestionID
+	record.ShownDocument = document
+	err = record.Transition(contract.StatusSent, "google form created")
+	if err == nil {
+		err = contractStore.Create(record)
+	}
 	if err != nil {
-		logger.WithError(err).Errorf("failed while cleaning up images")
+		logger.WithError(err).WithField("formId", form.FormID).Errorf("failed while saving contract")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Errorf("failed while cleaning up images with err : %w", err).Error(),
+			"error": fmt.Errorf("failed while saving contract for form %s with err : %w", form.FormID, err).Error(),
 		})
 	}
+	saved = true
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Contract created successfully",
+		"message":         "Contract created successfully",
+		"id":              record.ID,
+		"form":            form,
+		"paymentSchedule": schedule,
 	})
 }
 
+// redeemPromoCode counts the use of the discount's promo code, if it has one, against the code's usage limit.
+func redeemPromoCode(discount *contract.Discount) error {
+	if discount == nil || discount.Code == "" {
+		return nil
+	}
+
+	promo, err := pricing.LookupPromoCode(discount.Code, time.Now())
+	if err != nil {
+		return err
+	}
+	_, err = contractStore.RedeemPromoCode(discount.Code, promo.MaxUses)
+	return err
+}
+
+// releasePromoCode gives back a use of the discount's promo code counted by redeemPromoCode.
+func releasePromoCode(discount *contract.Discount) error {
+	if discount == nil || discount.Code == "" {
+		return nil
+	}
+
+	return contractStore.ReleasePromoCode(discount.Code)
+}
+
 func main() {
-	// Create a new Fiber instance
-	app := fiber.New()
+	// Open the contract store
+	dbPath := os.Getenv("CONTRACTS_DB_PATH")
+	if dbPath == "" {
+		dbPath = "contracts.db"
+	}
+
+	store, err := contract.NewSQLiteStore(dbPath)
+	if err != nil {
+		log.Fatalf("Error opening contract store at %s: %v", dbPath, err)
+	}
+	defer store.Close()
+	contractStore = store
+
+	// Connect to Google Drive and Forms, or fake them when running offline
+	if os.Getenv("GOOGLE_BACKEND") == "fake" {
+		fileStore = gformscreator.NewFakeFileStore()
+		formBuilder = gformscreator.NewFakeFormBuilder()
+	} else {
+		driveService, err := gformscreator.NewDriveService()
+		if err != nil {
+			log.Fatalf("Error connecting to Google Drive: %v", err)
+		}
+		formsService, err := gformscreator.NewFormsService()
+		if err != nil {
+			log.Fatalf("Error connecting to Google Forms: %v", err)
+		}
+		fileStore = driveService
+		formBuilder = formsService
+	}
+
+	// Load the payment policies offered on top of the built in ones
+	if policiesPath := os.Getenv("PAYMENT_POLICIES_PATH"); policiesPath != "" {
+		if err := pricing.LoadPolicies(policiesPath); err != nil {
+			log.Fatalf("Error loading payment policies from %s: %v", policiesPath, err)
+		}
+	}
+
+	// Load the packages contracts can be created from on top of the built in ones
+	if packagesPath := os.Getenv("PACKAGES_PATH"); packagesPath != "" {
+		if err := catalog.LoadPackages(packagesPath); err != nil {
+			log.Fatalf("Error loading packages from %s: %v", packagesPath, err)
+		}
+	}
+
+	// Load the promo codes contracts can be discounted with
+	if promoCodesPath := os.Getenv("PROMO_CODES_PATH"); promoCodesPath != "" {
+		if err := pricing.LoadPromoCodes(promoCodesPath); err != nil {
+			log.Fatalf("Error loading promo codes from %s: %v", promoCodesPath, err)
+		}
+	}
+
+	// Load the tax rates of line item categories on top of the built in ones
+	if taxRatesPath := os.Getenv("TAX_RATES_PATH"); taxRatesPath != "" {
+		if err := pricing.LoadTaxRates(taxRatesPath); err != nil {
+			log.Fatalf("Error loading tax rates from %s: %v", taxRatesPath, err)
+		}
+	}
+
+	// Load the versions of the terms on top of the built in ones, then pick the one new contracts are issued under
+	if termsPath := os.Getenv("TERMS_PATH"); termsPath != "" {
+		if err := terms.LoadTemplates(termsPath); err != nil {
+			log.Fatalf("Error loading terms from %s: %v", termsPath, err)
+		}
+	}
+	if termsVersion := os.Getenv("TERMS_VERSION"); termsVersion != "" {
+		if err := terms.SetCurrent(termsVersion); err != nil {
+			log.Fatalf("Error setting TERMS_VERSION %s: %v", termsVersion, err)
+		}
+	}
+
+	// Load the studio the documents are issued by in place of the built in one
+	if brandingPath := os.Getenv("BRANDING_PATH"); brandingPath != "" {
+		if err := branding.Load(brandingPath); err != nil {
+			log.Fatalf("Error loading branding from %s: %v", brandingPath, err)
+		}
+	}
+
+	// Poll the contract forms for signatures when an interval is configured
+	if pollInterval := os.Getenv("SIGNATURE_POLL_INTERVAL"); pollInterval != "" {
+		interval, err := time.ParseDuration(pollInterval)
+		if err != nil {
+			log.Fatalf("Error parsing SIGNATURE_POLL_INTERVAL %s: %v", pollInterval, err)
+		}
+		startSignaturePoller(interval)
+	}
+
+	app := newApp()
+
+	port := 8080
+	err = app.Listen(fmt.Sprintf(":%d", port))
+	if err != nil {
+		log.Fatalf("Error starting server on port %d: %v", port, err)
+	}
+}
+
+// newApp registers the middleware and routes of the service.
+func newApp() *fiber.App {
+	// Create a new Fiber instance. The client address is only read from X-Forwarded-For when the request came
+	// through one of TRUSTED_PROXIES, anyone else could put any address in it.
+	app := fiber.New(fiber.Config{
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          trustedProxies(),
+		ProxyHeader:             fiber.HeaderXForwardedFor,
+	})
 
 	// Register the logger middleware
 	app.Use(logger.New())
 
+	// Answer requests that hit a bug, such as amounts in different currencies being added up, with a 500
+	app.Use(recover.New())
+
 	config := cors.Config{
 		AllowOrigins:     "http://localhost:3000,https://rds-contracts-ui.vercel.app",
 		AllowMethods:     "GET,POST,OPTIONS",
@@ -122,11 +304,50 @@ func main() {
 	// Handle a new contract
 	app.Post("/newcontract", NewContractHandler)
 
-	port := 8080
-	err := app.Listen(fmt.Sprintf(":%d", port))
-	if err != nil {
-		log.Fatalf("Error starting server on port %d: %v", port, err)
+	// Offer the package catalog
+	app.Get("/packages", ListPackagesHandler)
+	app.Get("/terms", GetTermsHandler)
+
+	// Look up previously issued contracts
+	app.Get("/contracts", ListContractsHandler)
+	app.Get("/contracts/:id", GetContractHandler)
+	app.Get("/contracts/:id/pdf", ContractPdfHandler)
+
+	// Read signatures back from the contract forms
+	app.Post("/contracts/signatures/sync", SyncSignaturesHandler)
+
+	// Move contracts through their lifecycle
+	app.Post("/contracts/:id/status", UpdateContractStatusHandler)
+	app.Post("/contracts/:id/cancel", CancelContractHandler)
+	app.Post("/contracts/:id/reschedule", RescheduleContractHandler)
+
+	// Keep a ledger of the payments received for contracts
+	app.Get("/contracts/:id/payments", ListPaymentsHandler)
+	app.Post("/contracts/:id/payments", RecordPaymentHandler)
+	app.Get("/contracts/:id/invoice", InvoiceHandler)
+	app.Get("/contracts/:id/payments/:pid/receipt", ReceiptHandler)
+	app.Post("/contracts/:id/overtime", RecordOvertimeHandler)
+
+	// Sign contracts on our own signing page
+	app.Post("/contracts/:id/signing-link", SigningLinkHandler)
+	app.Get("/contracts/:id/signed-pdf", SignedContractHandler)
+	app.Get("/sign/:id/:token", SigningPageHandler)
+	app.Post("/sign/:id/:token", SignContractHandler)
+	app.Get("/sign/:id/:token/document", SigningDocumentHandler)
+	app.Get("/sign/:id/:token/signed", SignedDocumentHandler)
+
+	return app
+}
+
+// trustedProxies are the addresses and CIDR ranges of the proxies in TRUSTED_PROXIES, separated by commas.
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
 	}
+	return proxies
 }
 
 func ValidateContract(contract *contract.Contract) error {
@@ -144,30 +365,74 @@ func ValidateContract(contract *contract.Contract) error {
 		return errors.New("event name is required")
 	}
 
-	if contract.EventDetails.EventDate == "" {
-		return errors.New("event date is required")
-	}
-
-	if contract.EventDetails.EventCoverageTime == "" {
-		return errors.New("event coverage time is required")
+	if err := validateSchedule(contract); err != nil {
+		return err
 	}
 
 	if contract.EventDetails.EventVenue == "" {
 		return errors.New("event venue is required")
 	}
 
-	if contract.PaymentDetails.TotalAmount <= 0 {
+	if err := validateEventType(&contract.EventDetails); err != nil {
+		return err
+	}
+
+	if !money.Supported(contract.PaymentDetails.Currency) {
+		return fmt.Errorf("%w : %s is not supported", money.ErrUnknownCurrency, contract.PaymentDetails.Currency)
+	}
+
+	for _, amount := range contract.PaymentDetails.Amounts() {
+		if amount.Currency != contract.PaymentDetails.Currency {
+			return fmt.Errorf("%w : every amount should be in %s", money.ErrCurrencyMismatch, contract.PaymentDetails.Currency)
+		}
+	}
+
+	if contract.PaymentDetails.TotalAmount.Amount <= 0 {
 		return errors.New("total amount should be greater than zero")
 	}
 
-	if contract.PaymentDetails.AdvancePaid < 0 {
+	if contract.PaymentDetails.AdvancePaid.IsNegative() {
 		return errors.New("advance paid cannot be negative")
 	}
 
-	if contract.PaymentDetails.PerHourExtra < 0 {
+	if contract.PaymentDetails.PerHourExtra.IsNegative() {
 		return errors.New("per hour extra cannot be negative")
 	}
 
+	for i, item := range contract.PaymentDetails.LineItems {
+		if item.Description == "" {
+			return fmt.Errorf("line item %d description is required", i+1)
+		}
+		if item.Amount.Amount <= 0 {
+			return fmt.Errorf("line item %d amount should be greater than zero", i+1)
+		}
+		if _, err := pricing.LookupTaxRate(item.TaxCategory); err != nil {
+			return fmt.Errorf("line item %d : %w", i+1, err)
+		}
+	}
+
+	if discount := contract.PaymentDetails.Discount; discount != nil {
+		// Promo codes decide the discount themselves, whatever else was sent with them
+		if discount.Code != "" {
+			promo, err := pricing.LookupPromoCode(discount.Code, time.Now())
+			if err != nil {
+				return err
+			}
+			contract.PaymentDetails.Discount = promo.Discount(discount.Code, contract.PaymentDetails.Currency)
+		} else if discount.Reason == "" {
+			return errors.New("discount reason is required")
+		}
+	}
+
+	charges, err := pricing.NewCharges(contract.PaymentDetails)
+	if err != nil {
+		return err
+	}
+
+	if err := validateInstallments(&contract.PaymentDetails, charges.GrandTotal); err != nil {
+		return err
+	}
+
 	if len(contract.DeliverableDetails) == 0 {
 		return errors.New("at least one deliverable is required")
 	}
@@ -184,11 +449,176 @@ func ValidateContract(contract *contract.Contract) error {
 		if deliverable.Mode == "" {
 			return errors.New("deliverable mode is required")
 		}
+	}
+
+	// Negotiated clauses are checked against the terms new contracts are issued under
+	currentTerms, err := terms.Lookup(terms.Current())
+	if err != nil {
+		return err
+	}
+	if err := currentTerms.ValidateCustomClauses(contract); err != nil {
+		return err
+	}
 
-		if deliverable.DeliveryDate == "" {
+	return nil
+}
+
+// validateSchedule checks the sessions, date and coverage time of the event can be read and are still to come, and
+// that every deliverable is due after the event, from a session it has. Reschedules check the contract again with it.
+func validateSchedule(contract *contract.Contract) error {
+	if len(contract.EventDetails.Sessions) > 0 {
+		if err := validateSessions(contract.EventDetails.Sessions); err != nil {
+			return err
+		}
+		contract.EventDetails.UseFirstSession()
+	}
+
+	if err := validateEventDate(contract.EventDetails.EventDate); err != nil {
+		return err
+	}
+
+	if contract.EventDetails.EventCoverageTime.IsZero() {
+		return errors.New("event coverage time is required")
+	} else if err := contract.EventDetails.EventCoverageTime.Validate(); err != nil {
+		return fmt.Errorf("event %w", err)
+	}
+
+	if err := validateEventStart(contract.EventDetails); err != nil {
+		return err
+	}
+
+	for i, deliverable := range contract.DeliverableDetails {
+		if deliverable.DeliveryDate.IsZero() {
 			return errors.New("deliverable delivery date is required")
+		} else if !deliverable.DeliveryDate.Valid() {
+			return fmt.Errorf("deliverable delivery date %q is not a valid date", deliverable.DeliveryDate)
+		}
+
+		if !deliverable.DeliveryDate.After(contract.EventDetails.LastDate()) {
+			return fmt.Errorf("%s should be delivered after the event", deliverable.Description)
+		}
+
+		if deliverable.Session != "" {
+			session, ok := contract.EventDetails.Session(deliverable.Session)
+			if !ok {
+				return fmt.Errorf("%s is from a session the event does not have, %s", deliverable.Description, deliverable.Session)
+			}
+			contract.DeliverableDetails[i].Session = session.Name
 		}
 	}
 
 	return nil
 }
+
+// validateSessions checks every session of a split event has a name of its own, a date, coverage time and venue,
+// and that they are listed in the order they happen.
+func validateSessions(sessions []contract.Session) error {
+	names := map[string]bool{}
+	var previousStart time.Time
+	for i, session := range sessions {
+		if session.Name == "" {
+			return fmt.Errorf("session %d name is required", i+1)
+		}
+		if names[strings.ToLower(session.Name)] {
+			return fmt.Errorf("there is more than one session named %s", session.Name)
+		}
+		names[strings.ToLower(session.Name)] = true
+
+		if err := validateEventDate(session.Date); err != nil {
+			return fmt.Errorf("%s %w", session.Name, err)
+		}
+		if session.CoverageTime.IsZero() {
+			return fmt.Errorf("%s coverage time is required", session.Name)
+		} else if err := session.CoverageTime.Validate(); err != nil {
+			return fmt.Errorf("%s %w", session.Name, err)
+		}
+		if session.Venue == "" {
+			return fmt.Errorf("%s venue is required", session.Name)
+		}
+
+		start := session.CoverageTime.Start.On(session.Date, session.CoverageTime.Location())
+		if start.Before(previousStart) {
+			return fmt.Errorf("%s starts before the session ahead of it", session.Name)
+		}
+		previousStart = start
+	}
+	return nil
+}
+
+// validateEventDate checks an event date was given as an actual date.
+func validateEventDate(eventDate contract.Date) error {
+	if eventDate.IsZero() {
+		return errors.New("event date is required")
+	}
+	if !eventDate.Valid() {
+		return fmt.Errorf("event date %q is not a date like %s", eventDate, contract.EventDateLayout)
+	}
+	return nil
+}
+
+// validateEventType checks the event type is known, contracts that do not name one are events.
+func validateEventType(event *contract.EventDetails) error {
+	eventType, err := contract.ParseEventType(string(event.EventType))
+	if err != nil {
+		return err
+	}
+	event.EventType = eventType
+	return nil
+}
+
+// validateEventStart checks the event is still to come, going by its start time when it is known and its date
+// otherwise.
+func validateEventStart(event contract.EventDetails) error {
+	if start, ok := event.Starts(); ok {
+		if !start.After(time.Now()) {
+			return errors.New("event should start in the future")
+		}
+		return nil
+	}
+
+	today := contract.NewDate(time.Now().In(event.EventCoverageTime.Location()))
+	if today.After(event.EventDate) {
+		return errors.New("event date should be in the future")
+	}
+	return nil
+}
+
+// validateInstallments checks the installments are dated in order and add up to the grand total.
+func validateInstallments(payment *contract.PaymentDetails, grandTotal money.Money) error {
+	if len(payment.Installments) == 0 {
+		return nil
+	}
+
+	if !payment.AdvancePaid.IsZero() {
+		return errors.New("advance paid should be recorded as a paid installment")
+	}
+
+	var previousDueDate time.Time
+	for i, installment := range payment.Installments {
+		if installment.Description == "" {
+			return fmt.Errorf("installment %d description is required", i+1)
+		}
+
+		dueDate, ok := installment.ParsedDueDate()
+		if !ok {
+			return fmt.Errorf("installment %d due date is not a valid date", i+1)
+		}
+		if dueDate.Before(previousDueDate) {
+			return fmt.Errorf("installment %d is due before the installment ahead of it", i+1)
+		}
+		previousDueDate = dueDate
+
+		if len(installment.Modes) == 0 {
+			return fmt.Errorf("installment %d needs at least one accepted payment mode", i+1)
+		}
+
+		switch installment.Status {
+		case "", contract.InstallmentPending, contract.InstallmentPaid:
+		default:
+			return fmt.Errorf("installment %d status should be %s or %s", i+1, contract.InstallmentPending, contract.InstallmentPaid)
+		}
+	}
+
+	_, err := payment.InstallmentAmounts(grandTotal)
+	return err
+}
@@ -0,0 +1,518 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/branding"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/contract"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/gformscreator"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/money"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/pricing"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/terms"
+)
+
+const testContractJSON = `{
+	"clientDetails": {"clientName": "Priya Raman", "clientEmail": "priya@example.com"},
+	"eventDetails": {"eventName": "House Warming", "eventDate": "2030-05-20", "eventCoverageTime": "4pm - 8pm",
+		"eventVenue": "Boston, MA", "eventType": "wedding"},
+	"paymentDetails": {"totalAmount": 1500, "perHourExtra": 150},
+	"deliverableDetails": [{"description": "Edited photos", "quantity": "200", "mode": "Online gallery",
+		"deliveryDate": "2030-06-20"}]
+}`
+
+// testApp is the service with an in memory contract store and fake Google Drive and Forms.
+type testApp struct {
+	*fiber.App
+	t     *testing.T
+	files *gformscreator.FakeFileStore
+	forms *gformscreator.FakeFormBuilder
+}
+
+func newTestApp(t *testing.T) *testApp {
+	t.Helper()
+
+	app := &testApp{
+		t:     t,
+		files: gformscreator.NewFakeFileStore(),
+		forms: gformscreator.NewFakeFormBuilder(),
+	}
+	contractStore, fileStore, formBuilder = contract.NewMemoryStore(), app.files, app.forms
+	app.App = newApp()
+	return app
+}
+
+// do sends the request and returns the response with its body read.
+func (app *testApp) do(method, target, contentType string, body io.Reader) (*http.Response, []byte) {
+	app.t.Helper()
+
+	request := httptest.NewRequest(method, target, body)
+	if contentType != "" {
+		request.Header.Set(fiber.HeaderContentType, contentType)
+	}
+	response, err := app.Test(request, -1)
+	if err != nil {
+		app.t.Fatalf("%s %s : %v", method, target, err)
+	}
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		app.t.Fatalf("%s %s : %v", method, target, err)
+	}
+	return response, data
+}
+
+func (app *testApp) postJSON(target, body string, status int, into interface{}) {
+	app.t.Helper()
+
+	response, data := app.do(http.MethodPost, target, fiber.MIMEApplicationJSON, strings.NewReader(body))
+	if response.StatusCode != status {
+		app.t.Fatalf("POST %s = %d %s, want %d", target, response.StatusCode, data, status)
+	}
+	if into != nil {
+		if err := json.Unmarshal(data, into); err != nil {
+			app.t.Fatalf("POST %s : %v", target, err)
+		}
+	}
+}
+
+func (app *testApp) createContract(body string) string {
+	app.t.Helper()
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	app.postJSON("/newcontract", body, fiber.StatusOK, &created)
+	return created.ID
+}
+
+func (app *testApp) record(id string) *contract.Record {
+	app.t.Helper()
+
+	record, err := contractStore.Get(id)
+	if err != nil {
+		app.t.Fatalf("Get %s : %v", id, err)
+	}
+	return record
+}
+
+var errFakeFormBuilder = errors.New("forms are not available")
+
+var documentHashField = regexp.MustCompile(`name="documentHash" value="([^"]*)"`)
+
+// signingLink creates the signing link of the contract and returns its path along with the signing page.
+func (app *testApp) signingLink(id string) (string, []byte) {
+	app.t.Helper()
+
+	var link struct {
+		SigningURL string `json:"signingUrl"`
+	}
+	app.postJSON("/contracts/"+id+"/signing-link", "", fiber.StatusOK, &link)
+	signingURL, err := url.Parse(link.SigningURL)
+	if err != nil {
+		app.t.Fatalf("signing link %q : %v", link.SigningURL, err)
+	}
+
+	response, page := app.do(http.MethodGet, signingURL.Path, "", nil)
+	if response.StatusCode != fiber.StatusOK {
+		app.t.Fatalf("signing page = %d %s", response.StatusCode, page)
+	}
+	return signingURL.Path, page
+}
+
+// sign submits the signing page as the client, from the given address of X-Forwarded-For if there is one.
+func (app *testApp) sign(signingPath, documentHash, forwardedFor string) *http.Response {
+	app.t.Helper()
+
+	form := url.Values{"name": {"Priya Raman"}, "agree": {"true"}, "documentHash": {documentHash}}
+	request := httptest.NewRequest(http.MethodPost, signingPath, strings.NewReader(form.Encode()))
+	request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationForm)
+	if forwardedFor != "" {
+		request.Header.Set(fiber.HeaderXForwardedFor, forwardedFor)
+	}
+	response, err := app.Test(request, -1)
+	if err != nil {
+		app.t.Fatalf("POST %s : %v", signingPath, err)
+	}
+	return response
+}
+
+// signContract signs the contract on the signing page and returns it signed.
+func (app *testApp) signContract(id string) *contract.Record {
+	app.t.Helper()
+
+	signingPath, page := app.signingLink(id)
+	match := documentHashField.FindSubmatch(page)
+	if match == nil {
+		app.t.Fatalf("signing page has no document hash : %s", page)
+	}
+	if response := app.sign(signingPath, string(match[1]), ""); response.StatusCode != fiber.StatusSeeOther {
+		app.t.Fatalf("signing = %d, want 303", response.StatusCode)
+	}
+	return app.record(id)
+}
+
+func TestNewContract(t *testing.T) {
+	app := newTestApp(t)
+
+	var created struct {
+		ID   string `json:"id"`
+		Form struct {
+			FormID       string   `json:"formId"`
+			ResponderURI string   `json:"responderUri"`
+			EditURI      string   `json:"editUri"`
+			ImageFileIDs []string `json:"imageFileIds"`
+		} `json:"form"`
+	}
+	app.postJSON("/newcontract", testContractJSON, fiber.StatusOK, &created)
+	if created.Form.FormID == "" || created.Form.ResponderURI == "" || created.Form.EditURI == "" || len(created.Form.ImageFileIDs) == 0 {
+		t.Fatalf("created form = %+v", created.Form)
+	}
+
+	record := app.record(created.ID)
+	if record.Status != contract.StatusSent || record.FormID != created.Form.FormID || record.FormURL != created.Form.ResponderURI {
+		t.Fatalf("created contract is %s with form %q at %q", record.Status, record.FormID, record.FormURL)
+	}
+	if record.Studio == nil || *record.Studio != branding.Current().Studio {
+		t.Errorf("contract was issued by %+v", record.Studio)
+	}
+	if len(app.forms.Forms) != 1 || len(app.files.SharedForms) != 1 {
+		t.Errorf("%d forms were created and %d shared", len(app.forms.Forms), len(app.files.SharedForms))
+	}
+	// The page images stay behind the form but are no longer public
+	for _, fileID := range record.FormImageFileIDs {
+		file, ok := app.files.Files[fileID]
+		if !ok || file.Public {
+			t.Errorf("page image %s was deleted or left public", fileID)
+		}
+	}
+}
+
+func TestSyncSignatures(t *testing.T) {
+	app := newTestApp(t)
+	id := app.createContract(testContractJSON)
+	record := app.record(id)
+
+	if err := app.forms.Respond(record.FormID, "P. Raman"); err != nil {
+		t.Fatal(err)
+	}
+	app.postJSON("/contracts/signatures/sync", "", fiber.StatusOK, nil)
+
+	record = app.record(id)
+	if record.Status != contract.StatusSigned || record.Signature.Source != contract.SignatureSourceGoogleForm {
+		t.Fatalf("synced contract is %s with signature %+v", record.Status, record.Signature)
+	}
+	// The printed name does not match the client name, so staff review it
+	if !record.Signature.NeedsReview || record.SignedDocument == nil {
+		t.Errorf("signature needs review %v, signed document kept %v", record.Signature.NeedsReview, record.SignedDocument != nil)
+	}
+	if record.ShownDocument == nil || record.Signature.DocumentHash != contract.DocumentHash(record.ShownDocument) {
+		t.Error("the signature is not of the document the form pages were made from")
+	}
+}
+
+func TestSignContract(t *testing.T) {
+	app := newTestApp(t)
+	id := app.createContract(testContractJSON)
+
+	signingPath, page := app.signingLink(id)
+	if !bytes.Contains(page, []byte("Red Dot Studios Services Agreement")) {
+		t.Fatalf("signing page = %s", page)
+	}
+	match := documentHashField.FindSubmatch(page)
+	if match == nil {
+		t.Fatalf("signing page has no document hash : %s", page)
+	}
+	// The page offers the PDF the form was made from, kept when the contract was issued, and signs its hash
+	shown := app.record(id).ShownDocument
+	if !bytes.HasPrefix(shown, []byte("%PDF")) || string(match[1]) != contract.DocumentHash(shown) {
+		t.Fatalf("signing page hash %s is not of the document kept as shown", match[1])
+	}
+	if response, document := app.do(http.MethodGet, signingPath+"/document", "", nil); response.StatusCode != fiber.StatusOK || !bytes.Equal(document, shown) {
+		t.Errorf("GET %s/document = %d and not the document kept as shown", signingPath, response.StatusCode)
+	}
+
+	if response, _ := app.do(http.MethodGet, "/sign/"+id+"/wrong-token", "", nil); response.StatusCode != fiber.StatusNotFound {
+		t.Errorf("signing page with a wrong token = %d", response.StatusCode)
+	}
+	if response := app.sign(signingPath, "stale", ""); response.StatusCode != fiber.StatusConflict {
+		t.Errorf("signing a contract that changed since it was opened = %d, want 409", response.StatusCode)
+	}
+	// The address in X-Forwarded-For is only taken from trusted proxies, none are configured here
+	if response := app.sign(signingPath, string(match[1]), "203.0.113.9"); response.StatusCode != fiber.StatusSeeOther {
+		t.Fatalf("signing = %d, want 303", response.StatusCode)
+	}
+
+	record := app.record(id)
+	if record.Status != contract.StatusSigned || record.Signature == nil || record.Signature.Source != contract.SignatureSourceSigningPage {
+		t.Fatalf("signed contract is %s with signature %+v", record.Status, record.Signature)
+	}
+	if record.Signature.IPAddress == "203.0.113.9" {
+		t.Error("the signer's address was read from X-Forwarded-For sent by an untrusted client")
+	}
+	if record.Signature.DocumentHash != contract.DocumentHash(shown) || !bytes.Equal(record.ShownDocument, shown) {
+		t.Error("the signature is not of the document that was kept as shown")
+	}
+	if !bytes.HasPrefix(record.SignedDocument, []byte("%PDF")) {
+		t.Fatal("the signed document was not kept")
+	}
+	for _, target := range []string{"/contracts/" + id + "/signed-pdf", "/contracts/" + id + "/pdf", signingPath + "/signed"} {
+		response, document := app.do(http.MethodGet, target, "", nil)
+		if response.StatusCode != fiber.StatusOK || !bytes.Equal(document, record.SignedDocument) {
+			t.Errorf("GET %s = %d and not the signed document", target, response.StatusCode)
+		}
+	}
+	if response := app.sign(signingPath, string(match[1]), ""); response.StatusCode != fiber.StatusConflict {
+		t.Errorf("signing twice = %d, want 409", response.StatusCode)
+	}
+}
+
+func TestNewContractPreview(t *testing.T) {
+	app := newTestApp(t)
+
+	response, document := app.do(http.MethodPost, "/newcontract?preview=true", fiber.MIMEApplicationJSON, strings.NewReader(testContractJSON))
+	if response.StatusCode != fiber.StatusOK || !bytes.HasPrefix(document, []byte("%PDF")) {
+		t.Fatalf("preview = %d", response.StatusCode)
+	}
+
+	records, _ := contractStore.List(contract.Filter{})
+	if len(records) != 0 || len(app.forms.Forms) != 0 || len(app.files.Files) != 0 {
+		t.Errorf("preview saved %d contracts, %d forms and %d files", len(records), len(app.forms.Forms), len(app.files.Files))
+	}
+}
+
+// payment is what recording a payment responds with.
+type payment struct {
+	Payment struct {
+		ID string `json:"id"`
+	} `json:"payment"`
+	Status contract.Status `json:"status"`
+}
+
+func TestRecordPayment(t *testing.T) {
+	app := newTestApp(t)
+	id := app.createContract(testContractJSON)
+	app.signContract(id)
+
+	app.postJSON("/contracts/"+id+"/payments", `{"amount": 380, "mode": "Zelle"}`, fiber.StatusBadRequest, nil)
+
+	// The booking fee of 25% of 1500 rounded up to 10, the rest is due on the event day
+	var paid payment
+	app.postJSON("/contracts/"+id+"/payments", `{"amount": 380, "mode": "Zelle", "recordedBy": "Anita"}`, fiber.StatusOK, &paid)
+	if paid.Status != contract.StatusSigned || paid.Payment.ID == "" {
+		t.Errorf("contract with the booking fee paid is %s", paid.Status)
+	}
+
+	app.postJSON("/contracts/"+id+"/payments", `{"amount": 1500, "mode": "Cash", "recordedBy": "Anita"}`, fiber.StatusBadRequest, nil)
+	app.postJSON("/contracts/"+id+"/payments", `{"amount": 1120, "mode": "Cash", "recordedBy": "Anita"}`, fiber.StatusOK, &paid)
+	if paid.Status != contract.StatusPaid {
+		t.Errorf("contract paid in full is %s", paid.Status)
+	}
+
+	record := app.record(id)
+	if len(record.Ledger) != 2 || record.Ledger[1].Mode != "Cash" || record.Ledger[1].RecordedBy != "Anita" {
+		t.Errorf("ledger = %+v", record.Ledger)
+	}
+}
+
+func TestRescheduleContract(t *testing.T) {
+	app := newTestApp(t)
+	id := app.createContract(testContractJSON)
+	app.signContract(id)
+	app.postJSON("/contracts/"+id+"/payments", `{"amount": 1500, "mode": "Zelle", "recordedBy": "Anita"}`, fiber.StatusOK, nil)
+
+	// The photos are due on 2030-06-20, so the event cannot move past it without a new delivery date
+	app.postJSON("/contracts/"+id+"/reschedule", `{"eventDate": "2030-06-27"}`, fiber.StatusBadRequest, nil)
+	app.postJSON("/contracts/"+id+"/reschedule", `{"eventDate": "2030-06-27", "deliveryDates": ["2030-06-27"]}`, fiber.StatusBadRequest, nil)
+	app.postJSON("/contracts/"+id+"/reschedule", `{"eventDate": "2030-06-27", "eventCoverageTime": "8pm - 4pm"}`, fiber.StatusBadRequest, nil)
+	if record := app.record(id); len(record.Reschedules) != 0 || record.Contract.EventDetails.EventDate.String() != "May 20, 2030" {
+		t.Fatalf("rejected reschedules moved the event to %s", record.Contract.EventDetails.EventDate)
+	}
+
+	app.postJSON("/contracts/"+id+"/reschedule", `{"eventDate": "2030-06-27", "deliveryDates": ["2030-07-27"], "note": "venue change"}`, fiber.StatusOK, nil)
+	record := app.record(id)
+	if record.Contract.EventDetails.EventDate.String() != "June 27, 2030" || record.Contract.DeliverableDetails[0].DeliveryDate.String() != "July 27, 2030" {
+		t.Errorf("rescheduled to %s with photos due %s", record.Contract.EventDetails.EventDate, record.Contract.DeliverableDetails[0].DeliveryDate)
+	}
+}
+
+func TestInvoiceAndReceipt(t *testing.T) {
+	app := newTestApp(t)
+	id := app.createContract(testContractJSON)
+	app.signContract(id)
+
+	var paid payment
+	app.postJSON("/contracts/"+id+"/payments", `{"amount": 380, "mode": "Zelle", "recordedBy": "Anita"}`, fiber.StatusOK, &paid)
+
+	response, invoice := app.do(http.MethodGet, "/contracts/"+id+"/invoice", "", nil)
+	if response.StatusCode != fiber.StatusOK || !bytes.HasPrefix(invoice, []byte("%PDF")) {
+		t.Fatalf("invoice = %d", response.StatusCode)
+	}
+	if disposition := response.Header.Get(fiber.HeaderContentDisposition); !strings.Contains(disposition, "INV-000001.pdf") {
+		t.Errorf("invoice is sent as %q", disposition)
+	}
+	record := app.record(id)
+	if record.InvoiceNumber != "INV-000001" || record.Invoice == nil || record.Invoice.BalanceDue != money.FromMajor(1120, money.DefaultCurrency) {
+		t.Fatalf("invoice %q = %+v", record.InvoiceNumber, record.Invoice)
+	}
+
+	// Invoices keep their number and issue date when they are sent again, with the payments received since
+	app.postJSON("/contracts/"+id+"/payments", `{"amount": 1120, "mode": "Cash", "recordedBy": "Anita"}`, fiber.StatusOK, nil)
+	app.do(http.MethodGet, "/contracts/"+id+"/invoice", "", nil)
+	updated := app.record(id)
+	if updated.InvoiceNumber != "INV-000001" || !updated.Invoice.IssuedAt.Equal(record.Invoice.IssuedAt) {
+		t.Errorf("invoice was issued again as %q on %s", updated.InvoiceNumber, updated.Invoice.IssuedAt)
+	}
+	if !updated.Invoice.BalanceDue.IsZero() || updated.Invoice.Received != money.FromMajor(1500, money.DefaultCurrency) {
+		t.Errorf("invoice after paying in full = %+v", updated.Invoice)
+	}
+
+	for i := 0; i < 2; i++ {
+		response, receipt := app.do(http.MethodGet, "/contracts/"+id+"/payments/"+paid.Payment.ID+"/receipt", "", nil)
+		if response.StatusCode != fiber.StatusOK || !bytes.HasPrefix(receipt, []byte("%PDF")) {
+			t.Fatalf("receipt = %d", response.StatusCode)
+		}
+	}
+	if entry, ok := app.record(id).LedgerEntry(paid.Payment.ID); !ok || entry.ReceiptNumber != "RCT-000001" {
+		t.Errorf("payment = %+v", entry)
+	}
+	if response, _ := app.do(http.MethodGet, "/contracts/"+id+"/payments/missing/receipt", "", nil); response.StatusCode != fiber.StatusNotFound {
+		t.Errorf("receipt of a missing payment = %d", response.StatusCode)
+	}
+}
+
+func TestNewContractInvalid(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := map[string]string{
+		"bad json":                     `{`,
+		"unknown currency":             strings.Replace(testContractJSON, `"totalAmount": 1500`, `"currency": "XYZ", "totalAmount": 1500`, 1),
+		"doubled minus":                strings.Replace(testContractJSON, `"perHourExtra": 150`, `"perHourExtra": "--150"`, 1),
+		"coverage ending as it starts": strings.Replace(testContractJSON, "4pm - 8pm", "4pm - 4pm", 1),
+		"delivery before the event":    strings.Replace(testContractJSON, "2030-06-20", "2030-05-19", 1),
+		"unreadable event date":        strings.Replace(testContractJSON, "2030-05-20", "sometime in May", 1),
+		"override of a fixed clause": strings.Replace(testContractJSON, `"paymentDetails"`,
+			`"clauseOverrides": [{"key": "cancellation", "body": "Anytime."}], "paymentDetails"`, 1),
+		"unknown tax category": strings.Replace(testContractJSON, `"perHourExtra": 150`,
+			`"perHourExtra": 150, "lineItems": [{"description": "Mystery", "amount": 10, "taxCategory": "nope"}]`, 1),
+	}
+	for name, body := range tests {
+		response, data := app.do(http.MethodPost, "/newcontract", fiber.MIMEApplicationJSON, strings.NewReader(body))
+		if response.StatusCode != fiber.StatusBadRequest || !bytes.Contains(data, []byte(`"error"`)) {
+			t.Errorf("%s : %d %s", name, response.StatusCode, data)
+		}
+	}
+}
+
+func TestNewContractPromoCode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "promo.json")
+	if err := os.WriteFile(path, []byte(`{"FLOWER": {"percent": 10, "reason": "Flower show", "maxUses": 1}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := pricing.LoadPromoCodes(path); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pricing.ResetPromoCodes)
+	body := strings.Replace(testContractJSON, `"perHourExtra": 150`, `"perHourExtra": 150, "discount": {"code": "flower"}`, 1)
+
+	app := newTestApp(t)
+
+	// A contract that fails to be issued gives back the use of the code
+	app.forms.Err = errFakeFormBuilder
+	response, _ := app.do(http.MethodPost, "/newcontract", fiber.MIMEApplicationJSON, strings.NewReader(body))
+	if response.StatusCode != fiber.StatusInternalServerError {
+		t.Fatalf("contract with a failing form = %d", response.StatusCode)
+	}
+	app.forms.Err = nil
+
+	id := app.createContract(body)
+	discount := app.record(id).Contract.PaymentDetails.Discount
+	if discount == nil || discount.Code != "FLOWER" || discount.Percent != 10 {
+		t.Errorf("discount = %+v", discount)
+	}
+
+	response, data := app.do(http.MethodPost, "/newcontract", fiber.MIMEApplicationJSON, strings.NewReader(body))
+	if response.StatusCode != fiber.StatusConflict {
+		t.Errorf("second use of a code with one use = %d %s, want 409", response.StatusCode, data)
+	}
+}
+
+const testSessionsJSON = `{
+	"clientDetails": {"clientName": "Priya Raman", "clientEmail": "priya@example.com"},
+	"eventDetails": {"eventName": "Raman Wedding", "eventType": "wedding", "sessions": [
+		{"name": "Mehendi", "date": "2030-05-18", "coverageTime": "4pm - 8pm", "venue": "Home"},
+		{"name": "Reception", "date": "2030-05-20", "coverageTime": "7pm - 11pm", "venue": "Boston, MA"}]},
+	"paymentDetails": {"totalAmount": 4000, "perHourExtra": 200},
+	"deliverableDetails": [{"description": "Edited photos", "quantity": "600", "mode": "Online gallery",
+		"deliveryDate": "2030-05-25", "session": "reception"}]
+}`
+
+func TestNewContractSessions(t *testing.T) {
+	app := newTestApp(t)
+	id := app.createContract(testSessionsJSON)
+
+	// The event takes its date, coverage and venue from the first session
+	details := app.record(id).Contract
+	if details.EventDetails.EventDate.String() != "May 18, 2030" || details.EventDetails.EventVenue != "Home" {
+		t.Errorf("event details = %+v", details.EventDetails)
+	}
+	if details.DeliverableDetails[0].Session != "Reception" {
+		t.Errorf("deliverable is from session %q", details.DeliverableDetails[0].Session)
+	}
+
+	var recorded struct {
+		Overtime contract.Overtime `json:"overtime"`
+	}
+	app.postJSON("/contracts/"+id+"/overtime", `{"session": "reception", "hours": 2, "recordedBy": "Anita"}`, fiber.StatusOK, &recorded)
+	if recorded.Overtime.Session != "Reception" || recorded.Overtime.Amount != money.FromMajor(400, money.DefaultCurrency) {
+		t.Errorf("overtime = %+v", recorded.Overtime)
+	}
+	app.postJSON("/contracts/"+id+"/overtime", `{"session": "Sangeet", "hours": 2, "recordedBy": "Anita"}`, fiber.StatusBadRequest, nil)
+
+	tests := map[string]string{
+		"delivery before the last session": strings.Replace(testSessionsJSON, "2030-05-25", "2030-05-19", 1),
+		"sessions out of order":            strings.Replace(testSessionsJSON, "2030-05-20", "2030-05-17", 1),
+		"session without a venue":          strings.Replace(testSessionsJSON, `"venue": "Home"`, `"venue": ""`, 1),
+		"sessions of the same name":        strings.Replace(testSessionsJSON, `"name": "Mehendi"`, `"name": "reception"`, 1),
+		"deliverable from no session":      strings.Replace(testSessionsJSON, `"session": "reception"`, `"session": "sangeet"`, 1),
+	}
+	for name, body := range tests {
+		response, data := app.do(http.MethodPost, "/newcontract", fiber.MIMEApplicationJSON, strings.NewReader(body))
+		if response.StatusCode != fiber.StatusBadRequest {
+			t.Errorf("%s : %d %s", name, response.StatusCode, data)
+		}
+	}
+}
+
+func TestTermsVersion(t *testing.T) {
+	app := newTestApp(t)
+	id := app.createContract(testContractJSON)
+	if version := app.record(id).TermsVersion; version != terms.Current() {
+		t.Errorf("contract was issued under terms %q, want %q", version, terms.Current())
+	}
+
+	response, data := app.do(http.MethodGet, "/terms?version=v1", "", nil)
+	var body struct {
+		Current string `json:"current"`
+		Terms   struct {
+			Version string `json:"version"`
+		} `json:"terms"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil || response.StatusCode != fiber.StatusOK || body.Terms.Version != "v1" {
+		t.Errorf("GET /terms?version=v1 = %d %s", response.StatusCode, data)
+	}
+	if response, _ := app.do(http.MethodGet, "/terms?version=v0", "", nil); response.StatusCode != fiber.StatusNotFound {
+		t.Errorf("GET /terms of an unknown version = %d", response.StatusCode)
+	}
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/catalog"
+)
+
+// ListPackagesHandler returns the package catalog, for the UI to offer as a starting point for new contracts.
+func ListPackagesHandler(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"packages": catalog.Packages(),
+	})
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/contract"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/money"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/pdfcreator"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/pricing"
+)
+
+// documentNumberLock keeps two requests from giving a contract or payment different invoice or receipt numbers.
+var documentNumberLock sync.Mutex
+
+type PaymentRequest struct {
+	// Amount is in the contract's currency when sent as a plain number.
+	Amount    money.Money `json:"amount"`
+	Mode      string      `json:"mode"`
+	Reference string      `json:"reference"`
+	// ReceivedAt is RFC 3339, defaults to when the payment is recorded.
+	ReceivedAt string `json:"receivedAt"`
+	RecordedBy string `json:"recordedBy"`
+}
+
+type OvertimeRequest struct {
+	// Session is the name of the session that ran over, the event name when the event is not split in sessions.
+	Session    string  `json:"session"`
+	Hours      float64 `json:"hours"`
+	Note       string  `json:"note"`
+	RecordedBy string  `json:"recordedBy"`
+}
+
+// RecordOvertimeHandler charges the hours a session ran past its coverage time at the contract's per hour extra.
+func RecordOvertimeHandler(c *fiber.Ctx) error {
+	var request OvertimeRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to parse JSON",
+		})
+	}
+
+	record, err := contractStore.Get(c.Params("id"))
+	if err != nil {
+		return contractErrorResponse(c, err, "failed while reading contract")
+	}
+
+	overtime, err := record.RecordOvertime(contract.Overtime{
+		Session:    request.Session,
+		Hours:      request.Hours,
+		Note:       request.Note,
+		RecordedBy: request.RecordedBy,
+	})
+	if err != nil {
+		return contractErrorResponse(c, err, "failed while recording overtime")
+	}
+
+	schedule, err := pricing.ScheduleFor(record)
+	if err != nil {
+		return contractErrorResponse(c, err, "failed while working out the payment schedule")
+	}
+
+	if err := contractStore.Update(record); err != nil {
+		return contractErrorResponse(c, err, "failed while saving contract")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"overtime":        overtime,
+		"paymentSchedule": schedule,
+	})
+}
+
+// ListPaymentsHandler returns the payments received for a contract and what is still outstanding.
+func ListPaymentsHandler(c *fiber.Ctx) error {
+	record, err := contractStore.Get(c.Params("id"))
+	if err != nil {
+		return contractErrorResponse(c, err, "failed while reading contract")
+	}
+
+	schedule, err := pricing.ScheduleFor(record)
+	if err != nil {
+		return contractErrorResponse(c, err, "failed while working out the payment schedule")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"payments":        record.Ledger,
+		"paymentSchedule": schedule,
+	})
+}
+
+// RecordPaymentHandler adds a received payment to a contract's ledger. Contracts that are signed move to paid once
+// nothing is outstanding.
+func RecordPaymentHandler(c *fiber.Ctx) error {
+	var request PaymentRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to parse JSON",
+		})
+	}
+
+	entry := contract.LedgerEntry{
+		Amount:     request.Amount,
+		Mode:       request.Mode,
+		Reference:  request.Reference,
+		RecordedBy: request.RecordedBy,
+	}
+	if request.ReceivedAt != "" {
+		receivedAt, err := time.Parse(time.RFC3339, request.ReceivedAt)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "receivedAt should be formatted as RFC 3339",
+			})
+		}
+		entry.ReceivedAt = receivedAt
+	}
+
+	record, err := contractStore.Get(c.Params("id"))
+	if err != nil {
+		return contractErrorResponse(c, err, "failed while reading contract")
+	}
+
+	schedule, err := pricing.ScheduleFor(record)
+	if err != nil {
+		return contractErrorResponse(c, err, "failed while working out the payment schedule")
+	}
+
+	if entry.Amount.Amount > schedule.Outstanding.Amount {
+		err = fmt.Errorf("%w : only %s is outstanding", contract.ErrInvalidPayment, schedule.Outstanding)
+		return contractErrorResponse(c, err, "failed while recording payment")
+	}
+
+	payment, err := record.RecordPayment(entry)
+	if err != nil {
+		return contractErrorResponse(c, err, "failed while recording payment")
+	}
+
+	schedule.ApplyLedger(record.Ledger[len(record.Ledger)-1:])
+	if schedule.Outstanding.IsZero() && record.Status == contract.StatusSigned {
+		if err := record.Transition(contract.StatusPaid, "project payment received in full"); err != nil {
+			return contractErrorResponse(c, err, "failed while updating contract")
+		}
+	}
+
+	if err := contractStore.Update(record); err != nil {
+		return contractErrorResponse(c, err, "failed while saving contract")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"payment":         payment,
+		"paymentSchedule": schedule,
+		"status":          record.Status,
+	})
+}
+
+// InvoiceHandler sends the invoice of a contract. The first time it is issued it is numbered, later requests send
+// the invoice under the same number with the payments and the balance due as they stand.
+func InvoiceHandler(c *fiber.Ctx) error {
+	documentNumberLock.Lock()
+	defer documentNumberLock.Unlock()
+
+	record, err := contractStore.Get(c.Params("id"))
+	if err != nil {
+		return contractErrorResponse(c, err, "failed while reading contract")
+	}
+
+	schedule, err := pricing.ScheduleFor(record)
+	if err != nil {
+		return contractErrorResponse(c, err, "failed while working out the payment schedule")
+	}
+
+	// Contracts numbered before invoices were kept keep their number
+	if record.InvoiceNumber == "" {
+		number, err := contractStore.NextNumber("invoice")
+		if err != nil {
+			return contractErrorResponse(c, err, "failed while numbering invoice")
+		}
+		record.InvoiceNumber = fmt.Sprintf("INV-%06d", number)
+	}
+
+	// The invoice keeps its number and issue date, the payments received and the balance due are brought up to date
+	// from the ledger every time it is sent
+	issuedAt := time.Now().UTC()
+	if record.Invoice != nil {
+		issuedAt = record.Invoice.IssuedAt
+	}
+	invoice := schedule.Invoice(issuedAt)
+	if !reflect.DeepEqual(invoice, record.Invoice) {
+		record.Invoice = invoice
+		if err := contractStore.Update(record); err != nil {
+			return contractErrorResponse(c, err, "failed while saving contract")
+		}
+	}
+
+	document, err := pdfcreator.RenderInvoice(record)
+	if err != nil {
+		return contractErrorResponse(c, err, "failed while rendering invoice")
+	}
+
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", record.InvoiceNumber+".pdf"))
+	c.Type("pdf")
+	return c.Send(document)
+}
+
+// ReceiptHandler sends the receipt of a payment, numbering it the first time it is issued.
+func ReceiptHandler(c *fiber.Ctx) error {
+	documentNumberLock.Lock()
+	defer documentNumberLock.Unlock()
+
+	record, err := contractStore.Get(c.Params("id"))
+	if err != nil {
+		return contractErrorResponse(c, err, "failed while reading contract")
+	}
+
+	entry, ok := record.LedgerEntry(c.Params("pid"))
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "payment not found",
+		})
+	}
+
+	// The receipt shows the balance as it was right after this payment
+	schedule, err := pricing.NewSchedule(record.Contract.PaymentDetails)
+	if err != nil {
+		return contractErrorResponse(c, err, "failed while working out the payment schedule")
+	}
+	var overtime []contract.Overtime
+	for _, entryOvertime := range record.Overtime {
+		if !entryOvertime.RecordedAt.After(entry.RecordedAt) {
+			overtime = append(overtime, entryOvertime)
+		}
+	}
+	schedule.AddOvertime(overtime)
+	for i := range record.Ledger {
+		if record.Ledger[i].ID == entry.ID {
+			schedule.ApplyLedger(record.Ledger[:i+1])
+			break
+		}
+	}
+
+	if entry.ReceiptNumber == "" {
+		number, err := contractStore.NextNumber("receipt")
+		if err != nil {
+			return contractErrorResponse(c, err, "failed while numbering receipt")
+		}
+
+		entry.ReceiptNumber = fmt.Sprintf("RCT-%06d", number)
+		if err := contractStore.Update(record); err != nil {
+			return contractErrorResponse(c, err, "failed while saving contract")
+		}
+	}
+
+	document, err := pdfcreator.RenderReceipt(record, entry, schedule)
+	if err != nil {
+		return contractErrorResponse(c, err, "failed while rendering receipt")
+	}
+
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", entry.ReceiptNumber+".pdf"))
+	c.Type("pdf")
+	return c.Send(document)
+}
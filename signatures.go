@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/contract"
+)
+
+// signatureSyncLock keeps the poller and the sync endpoint from signing the same contract twice.
+var signatureSyncLock sync.Mutex
+
+// SignatureSyncResult lists the contracts a signature sync looked at.
+type SignatureSyncResult struct {
+	Checked     int               `json:"checked"`
+	Signed      []string          `json:"signed"`
+	NeedsReview []string          `json:"needsReview"`
+	Failed      map[string]string `json:"failed,omitempty"`
+}
+
+// SyncSignatures reads the form responses of every contract waiting for a signature and marks the signed ones.
+func SyncSignatures() (*SignatureSyncResult, error) {
+	signatureSyncLock.Lock()
+	defer signatureSyncLock.Unlock()
+
+	records, err := contractStore.List(contract.Filter{Status: contract.StatusSent})
+	if err != nil {
+		return nil, fmt.Errorf("failed while listing sent contracts with err : %w", err)
+	}
+
+	result := &SignatureSyncResult{Signed: []string{}, NeedsReview: []string{}, Failed: map[string]string{}}
+	for _, listed := range records {
+		if listed.FormID == "" {
+			continue
+		}
+		result.Checked++
+
+		response, err := formBuilder.FetchSignatureResponse(listed.FormID, listed.FormSignatureQuestionID)
+		if err != nil {
+			result.Failed[listed.ID] = err.Error()
+			continue
+		}
+		if response == nil {
+			continue
+		}
+
+		// Listed contracts leave out their documents, the signature is of the PDF the form pages were made from
+		record, err := contractStore.Get(listed.ID)
+		if err != nil {
+			result.Failed[listed.ID] = err.Error()
+			continue
+		}
+
+		err = signRecord(record, contract.Signature{
+			Name:     response.Name,
+			SignedAt: response.SubmittedAt,
+			Source:   contract.SignatureSourceGoogleForm,
+		})
+		if err == nil {
+			err = contractStore.Update(record)
+		}
+		if err != nil {
+			result.Failed[record.ID] = err.Error()
+			continue
+		}
+
+		result.Signed = append(result.Signed, record.ID)
+		if record.Signature.NeedsReview {
+			result.NeedsReview = append(result.NeedsReview, record.ID)
+		}
+	}
+
+	return result, nil
+}
+
+func SyncSignaturesHandler(c *fiber.Ctx) error {
+	logger := logrus.New()
+
+	result, err := SyncSignatures()
+	if err != nil {
+		logger.WithError(err).Errorf("failed while syncing signatures")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": fmt.Errorf("failed while syncing signatures with err : %w", err).Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(result)
+}
+
+// startSignaturePoller syncs signatures in the background every interval.
+func startSignaturePoller(interval time.Duration) {
+	logger := logrus.New()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			result, err := SyncSignatures()
+			if err != nil {
+				logger.WithError(err).Errorf("failed while polling for signatures")
+				continue
+			}
+
+			logger.WithFields(logrus.Fields{
+				"checked":     result.Checked,
+				"signed":      result.Signed,
+				"needsReview": result.NeedsReview,
+				"failed":      result.Failed,
+			}).Info("Polled for signatures")
+		}
+	}()
+}
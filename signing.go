@@ -0,0 +1,259 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/branding"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/contract"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/pdfcreator"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/signing"
+)
+
+// SigningLinkHandler creates the link a client opens to sign a contract on our own signing page.
+func SigningLinkHandler(c *fiber.Ctx) error {
+	record, err := contractStore.Get(c.Params("id"))
+	if err != nil {
+		return contractErrorResponse(c, err, "failed while reading contract")
+	}
+
+	if record.Status == contract.StatusDraft {
+		err = record.Transition(contract.StatusSent, "signing link created")
+		if err != nil {
+			return contractErrorResponse(c, err, "failed while sending contract")
+		}
+	}
+
+	if record.Status != contract.StatusSent {
+		err = fmt.Errorf("%w : a %s contract cannot be signed", contract.ErrInvalidTransition, record.Status)
+		return contractErrorResponse(c, err, "failed while creating signing link")
+	}
+
+	if record.SigningToken == "" {
+		record.SigningToken, err = signing.NewToken()
+		if err != nil {
+			return contractErrorResponse(c, err, "failed while creating signing link")
+		}
+	}
+
+	// Contracts issued before the PDF was kept are rendered once, the signing page shows and signs that copy
+	if record.ShownDocument == nil {
+		record.ShownDocument, err = pdfcreator.RenderContract(record)
+		if err != nil {
+			return contractErrorResponse(c, err, "failed while rendering contract pdf")
+		}
+	}
+
+	err = contractStore.Update(record)
+	if err != nil {
+		return contractErrorResponse(c, err, "failed while saving contract")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"id":         record.ID,
+		"signingUrl": publicBaseURL(c) + signingPath(record),
+	})
+}
+
+// SignedContractHandler returns the signed contract with its audit certificate.
+func SignedContractHandler(c *fiber.Ctx) error {
+	record, err := contractStore.Get(c.Params("id"))
+	if err != nil {
+		return contractErrorResponse(c, err, "failed while reading contract")
+	}
+
+	return sendSignedContract(c, record)
+}
+
+func SigningPageHandler(c *fiber.Ctx) error {
+	record, err := signingRecord(c)
+	if err != nil {
+		return err
+	}
+
+	return renderSigningPage(c, fiber.StatusOK, record, "")
+}
+
+// SigningDocumentHandler sends the contract PDF the client is asked to sign, as it was issued.
+func SigningDocumentHandler(c *fiber.Ctx) error {
+	record, err := signingRecord(c)
+	if err != nil {
+		return err
+	}
+
+	if record.ShownDocument == nil {
+		return sendContractPdf(c, record, "inline", "contract")
+	}
+
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", pdfFileName(record, "contract")))
+	c.Type("pdf")
+	return c.Send(record.ShownDocument)
+}
+
+func SignedDocumentHandler(c *fiber.Ctx) error {
+	record, err := signingRecord(c)
+	if err != nil {
+		return err
+	}
+
+	return sendSignedContract(c, record)
+}
+
+func SignContractHandler(c *fiber.Ctx) error {
+	logger := logrus.New()
+
+	record, err := signingRecord(c)
+	if err != nil {
+		return err
+	}
+
+	name := strings.TrimSpace(c.FormValue("name"))
+	if name == "" {
+		return renderSigningPage(c, fiber.StatusBadRequest, record, "Please type your full name to sign.")
+	}
+
+	if c.FormValue("agree") != "true" {
+		return renderSigningPage(c, fiber.StatusBadRequest, record, "Please confirm that you agree to the Agreement.")
+	}
+
+	drawing, err := signing.ParseDrawing(c.FormValue("drawing"))
+	if err != nil {
+		return renderSigningPage(c, fiber.StatusBadRequest, record, "Your drawn signature could not be read, please clear it and try again.")
+	}
+
+	documentHash, err := record.ShownDocumentHash()
+	if err != nil {
+		logger.WithError(err).WithField("id", record.ID).Errorf("failed while hashing the contract shown")
+		return fiber.NewError(fiber.StatusInternalServerError, "failed while signing the agreement")
+	}
+
+	if c.FormValue("documentHash") != documentHash {
+		return renderSigningPage(c, fiber.StatusConflict, record, "The agreement was updated since you opened it, please review it again before signing.")
+	}
+
+	err = signRecord(record, contract.Signature{
+		Name:      name,
+		SignedAt:  time.Now().UTC(),
+		Source:    contract.SignatureSourceSigningPage,
+		Drawing:   drawing,
+		IPAddress: c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	})
+	if errors.Is(err, contract.ErrInvalidTransition) {
+		return renderSigningPage(c, fiber.StatusConflict, record, fmt.Sprintf("This agreement can no longer be signed, please contact %s.", branding.IssuedBy(record.Studio).Name))
+	}
+	if err == nil {
+		err = contractStore.Update(record)
+	}
+	if errors.Is(err, contract.ErrConflict) {
+		// The record was signed in memory only, the page shows the contract as it now stands
+		if record, err = signingRecord(c); err != nil {
+			return err
+		}
+		return renderSigningPage(c, fiber.StatusConflict, record, "The agreement was updated since you opened it, please review it again before signing.")
+	}
+	if err != nil {
+		logger.WithError(err).WithField("id", record.ID).Errorf("failed while signing contract")
+		return fiber.NewError(fiber.StatusInternalServerError, "failed while signing the agreement")
+	}
+
+	return c.Redirect(signingPath(record), fiber.StatusSeeOther)
+}
+
+// signingRecord loads the contract of a signing link, links with a wrong token are reported as not found.
+func signingRecord(c *fiber.Ctx) (*contract.Record, error) {
+	record, err := contractStore.Get(c.Params("id"))
+	if errors.Is(err, contract.ErrNotFound) || err == nil && !record.SigningTokenMatches(c.Params("token")) {
+		return nil, fiber.NewError(fiber.StatusNotFound, "this signing link is not valid")
+	}
+	if err != nil {
+		logrus.New().WithError(err).WithField("id", c.Params("id")).Errorf("failed while reading contract for signing")
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "failed while loading the agreement")
+	}
+
+	return record, nil
+}
+
+func renderSigningPage(c *fiber.Ctx, status int, record *contract.Record, message string) error {
+	documentHash, err := record.ShownDocumentHash()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed while loading the agreement")
+	}
+
+	page := &signing.Page{
+		StudioName:   branding.IssuedBy(record.Studio).Name,
+		ContractID:   record.ID,
+		ClientName:   record.Contract.ClientDetails.ClientName,
+		EventName:    record.Contract.EventDetails.EventName,
+		DocumentURL:  signingPath(record) + "/document",
+		SignedURL:    signingPath(record) + "/signed",
+		SubmitURL:    signingPath(record),
+		DocumentHash: documentHash,
+		Error:        message,
+	}
+	if record.Signature != nil {
+		page.Signed = true
+		page.SignedName = record.Signature.Name
+		page.SignedAt = record.Signature.SignedAt.UTC().Format("January 2, 2006 15:04 MST")
+	}
+
+	c.Status(status).Type("html")
+	return signing.RenderPage(c, page)
+}
+
+// signRecord signs the contract and keeps the signed copy, the audit certificate of which carries the SHA-256 of the
+// PDF the client was shown as it was kept when the contract was issued.
+func signRecord(record *contract.Record, signature contract.Signature) error {
+	documentHash, err := record.ShownDocumentHash()
+	if err != nil {
+		return err
+	}
+	signature.DocumentHash = documentHash
+
+	if err := record.Sign(signature); err != nil {
+		return err
+	}
+
+	signed, err := pdfcreator.RenderContract(record)
+	if err != nil {
+		return err
+	}
+	record.SignedDocument = signed
+
+	return nil
+}
+
+// sendSignedContract sends the signed contract as it was issued when the client signed. Contracts signed before
+// signed copies were kept are rendered from the record.
+func sendSignedContract(c *fiber.Ctx, record *contract.Record) error {
+	if record.Signature == nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "contract has not been signed yet",
+		})
+	}
+
+	if record.SignedDocument == nil {
+		return sendContractPdf(c, record, "attachment", "signed-contract")
+	}
+
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", pdfFileName(record, "signed-contract")))
+	c.Type("pdf")
+	return c.Send(record.SignedDocument)
+}
+
+func signingPath(record *contract.Record) string {
+	return fmt.Sprintf("/sign/%s/%s", record.ID, record.SigningToken)
+}
+
+// publicBaseURL is where clients reach this service, PUBLIC_BASE_URL overrides the URL of the request.
+func publicBaseURL(c *fiber.Ctx) string {
+	if baseURL := os.Getenv("PUBLIC_BASE_URL"); baseURL != "" {
+		return strings.TrimSuffix(baseURL, "/")
+	}
+	return c.BaseURL()
+}
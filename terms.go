@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/viggneshvn/reddotstudios_contracts_backend/internal/terms"
+)
+
+// GetTermsHandler returns a version of the terms, the current one unless the version query parameter names
+// another, so the UI can show the clause keys contracts can override.
+func GetTermsHandler(c *fiber.Ctx) error {
+	version := c.Query("version", terms.Current())
+	template, err := terms.Lookup(version)
+	if errors.Is(err, terms.ErrUnknownVersion) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"current":  terms.Current(),
+		"versions": terms.Versions(),
+		"terms":    template,
+	})
+}